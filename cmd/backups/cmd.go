@@ -0,0 +1,24 @@
+package backups
+
+import (
+	"github.com/clevyr/kubedb/cmd/backups/list"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backups",
+		Aliases: []string{"backup", "b"},
+		Short:   "Browse existing dumps",
+		GroupID: "ro",
+
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	cmd.AddCommand(
+		list.New(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,201 @@
+package list
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"gabe565.com/utils/bytefmt"
+	"gabe565.com/utils/must"
+	"github.com/clevyr/kubedb/internal/actions"
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/backups"
+	"github.com/clevyr/kubedb/internal/completion"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/config/flags"
+	"github.com/clevyr/kubedb/internal/consts"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list [dir | bucket URI]",
+		Aliases: []string{"ls"},
+		Short:   "List dumps in a directory or bucket",
+		Long: `List dumps in a directory or bucket.
+
+Dumps are matched by their generated filenames, which contain the namespace, database, and date.
+Use --namespace and --dbname to filter by source, and --since and --until to filter by date.
+Dates can be a timestamp like "2024-01-02 15:04:05", a date like "2024-01-02", or a duration like "72h".`,
+
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: validArgs,
+
+		PreRunE: preRun,
+		RunE:    run,
+	}
+
+	flags.FilenameTemplate(cmd)
+	flags.PVCAppPod(cmd)
+	cmd.Flags().StringP(consts.FlagDBName, "d", "", "Only list dumps of this database")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagDBName, cobra.NoFileCompletions))
+	cmd.Flags().String(consts.FlagSince, "", "Only list dumps newer than a date or duration")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSince, cobra.NoFileCompletions))
+	cmd.Flags().String(consts.FlagUntil, "", "Only list dumps older than a date or duration")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagUntil, cobra.NoFileCompletions))
+	cmd.Flags().StringP(consts.FlagOutput, "o", outputTable, "Output format (one of "+outputTable+", "+outputJSON+")")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagOutput,
+		cobra.FixedCompletions([]string{outputTable, outputJSON}, cobra.ShellCompDirectiveNoFileComp),
+	))
+
+	return cmd
+}
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var ErrInvalidOutput = errors.New("invalid output format")
+
+func preRun(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	action := &conftypes.Backups{Global: config.Global}
+
+	if err := config.Unmarshal(cmd, "backups", &action); err != nil {
+		return err
+	}
+	action.Dir = "."
+	if len(args) > 0 {
+		action.Dir = args[0]
+	}
+
+	switch action.Output {
+	case outputTable, outputJSON:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidOutput, action.Output)
+	}
+
+	cmd.SetContext(actions.NewContext(cmd.Context(), action))
+	return nil
+}
+
+func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if storage.IsCloud(toComplete) {
+		if err := completion.LoadConfig(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		u, err := url.Parse(toComplete)
+		if err != nil {
+			slog.Error("Failed to parse URL", "error", err)
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		if u.Host == "" || u.Path == "" {
+			return storage.CompleteBuckets(u)
+		}
+		return storage.CompleteObjects(u, nil, true)
+	}
+
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+type item struct {
+	Path      string           `json:"path"`
+	Namespace string           `json:"namespace"`
+	Database  string           `json:"database"`
+	Timestamp time.Time        `json:"timestamp"`
+	Size      int64            `json:"size"`
+	Format    sqlformat.Format `json:"format"`
+	Encrypted bool             `json:"encrypted"`
+	Manifest  bool             `json:"manifest"`
+	Parts     int              `json:"parts,omitempty"`
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	action := actions.FromContext[*conftypes.Backups](cmd.Context())
+
+	now := time.Now()
+	filter := backups.Filter{
+		Namespace: action.Namespace,
+		Database:  action.Database,
+	}
+	var err error
+	if filter.Since, err = backups.ParseTime(now, action.Since); err != nil {
+		return err
+	}
+	if filter.Until, err = backups.ParseTime(now, action.Until); err != nil {
+		return err
+	}
+
+	tmpl, err := dump.NewFilenameTemplate(action.FilenameTemplate)
+	if err != nil {
+		return err
+	}
+
+	list, err := backups.List(cmd.Context(), action.Dir, tmpl)
+	if err != nil {
+		return err
+	}
+
+	items := make([]item, 0, len(list))
+	for _, b := range list {
+		if !filter.Match(b) {
+			continue
+		}
+
+		group := b.Group()
+		items = append(items, item{
+			Path:      b.Path,
+			Namespace: group.Namespace,
+			Database:  group.Database,
+			Timestamp: b.Date,
+			Size:      b.Size,
+			Format:    b.Format(),
+			Encrypted: b.IsEncrypted(),
+			Manifest:  b.HasManifest,
+			Parts:     len(b.Parts),
+		})
+	}
+
+	if action.Output == outputJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	}
+
+	if len(items) == 0 {
+		slog.Info("No dumps found", "dir", action.Dir)
+		return nil
+	}
+
+	t := tui.ListTable(nil, "Namespace", "Database", "Timestamp", "Size", "Format")
+	for _, item := range items {
+		format := item.Format.String()
+		if item.Encrypted {
+			format += " (encrypted)"
+		}
+
+		t.Row(
+			tui.NamespaceStyle(nil, action.NamespaceColors, item.Namespace).Render(),
+			item.Database,
+			item.Timestamp.Format(time.DateTime),
+			bytefmt.Encode(item.Size),
+			format,
+		)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), t.Render())
+	return err
+}
@@ -11,9 +11,12 @@ import (
 	"runtime/debug"
 	"syscall"
 
+	"github.com/clevyr/kubedb/cmd/backups"
 	"github.com/clevyr/kubedb/cmd/dump"
 	"github.com/clevyr/kubedb/cmd/exec"
+	"github.com/clevyr/kubedb/cmd/jobs"
 	"github.com/clevyr/kubedb/cmd/portforward"
+	"github.com/clevyr/kubedb/cmd/prune"
 	"github.com/clevyr/kubedb/cmd/restore"
 	"github.com/clevyr/kubedb/cmd/status"
 	"github.com/clevyr/kubedb/internal/config"
@@ -74,7 +77,11 @@ func New() *cobra.Command {
 		dump.New(),
 		restore.New(),
 		portforward.New(),
+		prune.New(),
 		status.New(),
+		backups.New(),
+		jobs.New(),
+		dump.NewClusterUpload(),
 	)
 
 	return cmd
@@ -0,0 +1,28 @@
+package dump
+
+import (
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/spf13/cobra"
+)
+
+const flagDetached = "detached"
+
+// NewClusterUpload creates the command which runs in the job pod when dumping with --upload-from-cluster or --detach.
+func NewClusterUpload() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "cluster-upload",
+		Short:  "Upload a dump from inside the job pod",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SilenceUsage = true
+			if detached, _ := cmd.Flags().GetBool(flagDetached); detached {
+				return dump.ClusterUploadDetached(cmd.Context(), cmd.OutOrStdout())
+			}
+			return dump.ClusterUpload(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().Bool(flagDetached, false, "Load the request from the environment")
+	return cmd
+}
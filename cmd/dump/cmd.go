@@ -1,6 +1,7 @@
 package dump
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -15,11 +16,13 @@ import (
 	"gabe565.com/utils/must"
 	"github.com/clevyr/kubedb/internal/actions"
 	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/completion"
 	"github.com/clevyr/kubedb/internal/config"
 	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"github.com/clevyr/kubedb/internal/config/flags"
 	"github.com/clevyr/kubedb/internal/consts"
 	"github.com/clevyr/kubedb/internal/database"
+	"github.com/clevyr/kubedb/internal/ratelimit"
 	"github.com/clevyr/kubedb/internal/storage"
 	"github.com/clevyr/kubedb/internal/util"
 	"github.com/spf13/cobra"
@@ -27,12 +30,12 @@ import (
 
 func New() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "dump [filename | bucket URI]",
+		Use:     "dump [filename | bucket URI]...",
 		Aliases: []string{"d", "export"},
 		Short:   "Dump a database to a sql file",
 		Long:    newDescription(),
 
-		Args:              cobra.MaximumNArgs(1),
+		Args:              cobra.ArbitraryArgs,
 		ValidArgsFunction: validArgs,
 		GroupID:           "ro",
 
@@ -56,11 +59,36 @@ func New() *cobra.Command {
 	flags.ExcludeTableData(cmd)
 	flags.Quiet(cmd)
 	flags.RemoteGzip(cmd)
+	flags.RemoteZstd(cmd)
+	flags.LimitRate(cmd)
 	flags.Spinner(cmd)
 	flags.Opts(cmd)
+	flags.Recipients(cmd)
+	flags.Passphrase(cmd)
 	flags.Progress(cmd)
-	cmd.Flags().StringP(consts.FlagOutput, "o", "", "Output file path (can also be set using a positional arg)")
+	flags.FilenameTemplate(cmd)
+	flags.PVCAppPod(cmd)
+	flags.SSE(cmd)
+	flags.SSEKMSKeyID(cmd)
+	flags.StorageClass(cmd)
+	flags.Tags(cmd)
+	flags.Metadata(cmd)
+	flags.UploadFromCluster(cmd)
+	flags.UploadImage(cmd)
+	flags.UploadSecret(cmd)
+	flags.UploadServiceAccount(cmd)
+	cmd.Flags().StringSliceP(consts.FlagOutput, "o", nil,
+		"Output file path. Can be repeated to write the dump to multiple destinations (can also be set using positional args)",
+	)
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagOutput, validArgs))
+	cmd.Flags().Bool(consts.FlagDetach, false, "Run the dump in the job pod and return without waiting. Requires a cloud or pvc output")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagDetach, completion.BoolCompletion))
+	cmd.Flags().Bool(consts.FlagStaged, false, "Write the dump to the job pod before downloading it, so dropped connections can resume")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagStaged, completion.BoolCompletion))
+	cmd.Flags().String(consts.FlagSplitSize, "", `Split the dump into numbered volumes of this size, for example "1GiB"`)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSplitSize, func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"100MiB", "1GiB", "5GiB"}, cobra.ShellCompDirectiveNoFileComp
+	}))
 
 	return cmd
 }
@@ -72,22 +100,29 @@ func preRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	if len(args) > 0 {
-		action.Output = args[0]
+		if cmd.Flags().Lookup(consts.FlagOutput).Changed {
+			action.Output = append(action.Output, args...)
+		} else {
+			action.Output = args
+		}
 	}
 
 	if err := util.DefaultSetup(cmd, action.Global); err != nil {
 		return err
 	}
+	if !cmd.Flags().Lookup(consts.FlagLimitRate).Changed {
+		limit, err := ratelimit.ForNamespace(action.NamespaceLimitRates, action.Namespace, action.LimitRate)
+		if err != nil {
+			return err
+		}
+		action.LimitRate = limit
+	}
 
 	cmd.SetContext(actions.NewContext(cmd.Context(), action))
 	return nil
 }
 
 func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if len(args) != 0 || must.Must2(cmd.Flags().GetString(consts.FlagOutput)) != "" {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
-
 	must.Must(config.K.Set(consts.FlagCreateJob, false))
 	config.Global.SkipSurvey = true
 	err := preRun(cmd, args)
@@ -126,6 +161,20 @@ func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string,
 	return exts, cobra.ShellCompDirectiveFilterFileExt
 }
 
+var ErrFormatMismatch = errors.New("output extension does not match the dump format")
+
+func isDir(output string) bool {
+	if output == "" || strings.HasSuffix(output, string(os.PathSeparator)) || storage.IsCloudDir(output) {
+		return true
+	}
+	if !storage.IsCloud(output) {
+		if stat, err := os.Stat(output); err == nil {
+			return stat.IsDir()
+		}
+	}
+	return false
+}
+
 func run(cmd *cobra.Command, _ []string) error {
 	action := actions.FromContext[*dump.Dump](cmd.Context())
 
@@ -134,33 +183,123 @@ func run(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("%w: %s", util.ErrNoDump, action.Dialect.Name())
 	}
 
-	isDir := action.Output == "" || strings.HasSuffix(action.Output, string(os.PathSeparator)) ||
-		storage.IsCloudDir(action.Output)
-	if !isDir && !storage.IsCloud(action.Output) {
-		if stat, err := os.Stat(action.Output); err == nil {
-			isDir = stat.IsDir()
+	if len(action.Output) == 0 {
+		action.Output = []string{""}
+	}
+
+	dirs := make([]bool, len(action.Output))
+	detect := !cmd.Flags().Lookup(consts.FlagFormat).Changed
+	var detected bool
+	for i, output := range action.Output {
+		if storage.IsHTTP(output) {
+			return fmt.Errorf("%w: %s", storage.ErrReadOnly, output)
+		}
+
+		dirs[i] = isDir(output)
+		if dirs[i] || output == "-" || !detect {
+			continue
 		}
+
+		// All named outputs must agree on the format since only one dump is made
+		format := database.DetectFormat(db, output)
+		if detected && format != action.Format {
+			return fmt.Errorf("%w: %s", ErrFormatMismatch, output)
+		}
+		action.Format = format
+		detected = true
 	}
 
-	if isDir {
-		generated := dump.Filename{
-			Database:  action.Database,
-			Namespace: action.Client.Namespace,
-			Ext:       database.GetExtension(db, action.Format),
-			Date:      time.Now(),
-		}.Generate()
-		if storage.IsCloud(action.Output) {
-			u, err := url.Parse(action.Output)
+	var generated string
+	for i, output := range action.Output {
+		if !dirs[i] {
+			continue
+		}
+
+		if generated == "" {
+			tmpl, err := dump.NewFilenameTemplate(action.FilenameTemplate)
+			if err != nil {
+				return err
+			}
+
+			if generated, err = tmpl.Generate(dump.Filename{
+				Context:   action.Context,
+				Namespace: action.Client.Namespace,
+				Pod:       action.DBPod.Name,
+				Dialect:   action.Dialect.Name(),
+				Database:  action.Database,
+				Username:  action.Username,
+				Ext:       database.GetExtension(db, action.Format) + action.Ext(),
+				Date:      time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if storage.IsCloud(output) {
+			u, err := url.Parse(output)
 			if err != nil {
 				return err
 			}
 			u.Path = path.Join(u.Path, generated)
-			action.Output = u.String()
+			action.Output[i] = u.String()
 		} else {
-			action.Output = filepath.Join(action.Output, generated)
+			action.Output[i] = filepath.Join(output, generated)
 		}
-	} else if !cmd.Flags().Lookup(consts.FlagFormat).Changed {
-		action.Format = database.DetectFormat(db, action.Output)
+	}
+
+	if action.Detach {
+		// Detached dumps are always uploaded from the job pod
+		action.ClusterUpload.Enabled = true
+	}
+
+	if slices.ContainsFunc(action.Output, storage.IsPVC) {
+		// Claims are written by the uploader so the dump never leaves the cluster
+		claims, err := storage.PVCClaims(action.Output)
+		if err != nil {
+			return err
+		}
+		action.Claims = claims
+		action.ClusterUpload.Enabled = true
+	}
+
+	if action.ClusterUpload.Enabled {
+		if err := action.ValidateClusterUpload(); err != nil {
+			return err
+		}
+		ports, err := action.ClusterUploadPorts()
+		if err != nil {
+			return err
+		}
+		action.ClusterUpload.Ports = ports
+		if action.LimitRate != "" {
+			if cmd.Flags().Lookup(consts.FlagLimitRate).Changed {
+				slog.Warn("Rate limit is ignored when uploading from the cluster")
+			}
+			action.LimitRate = ""
+		}
+	}
+
+	if action.Staged {
+		if err := action.ValidateStaged(); err != nil {
+			return err
+		}
+		action.Stage = true
+	}
+
+	if action.Detach {
+		detached, err := action.Detached()
+		if err != nil {
+			return err
+		}
+		if err := util.CreateDetachedJob(cmd.Context(), cmd, action.Global, detached); err != nil {
+			return err
+		}
+		slog.Info("Dump is running in the background. Follow it with `kubedb jobs attach`",
+			"namespace", action.Namespace,
+			"job", action.Job.Name,
+		)
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), action.Job.Name)
+		return err
 	}
 
 	if err := util.CreateJob(cmd.Context(), cmd, action.Global); err != nil {
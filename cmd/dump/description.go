@@ -17,13 +17,35 @@ Supported Databases:
 
 File Path:
   - If the path is not provided, a filename will be generated.
+  - Multiple paths can be given to write the same dump to each of them. If one fails, the others are still written.
   - If the path is a file, the database will be dumped there.
   - If the path is a directory, the database will be dumped to a generated filename in that directory.
   - Filenames are autogenerated based on the namespace and timestamp.
+    The name can be customized with --filename-template. Slashes in the template create subdirectories.
+  - A manifest containing the SHA-256 checksum is written next to the dump as "<file>.json".
+  - With --split-size, the dump is written as "<file>.part001", "<file>.part002", etc.
+  - With --staged, the dump is written to the job pod first, then downloaded in a separate step.
+    If the connection drops, the download resumes where it left off, and the size and checksum are verified before the file is moved into place.
 
 Cloud Upload:
-	- Use "s3://" for S3, "gs://" for GCS, or "b2://" for Backblaze B2.
+	- Use "s3://" for S3, "gs://" for GCS, "b2://" for Backblaze B2, or "azblob://" for Azure Blob Storage.
+  - Use "sftp://user@host/path" to upload over SSH. Keys are loaded from ssh-agent or ~/.ssh, and hosts are verified with known_hosts.
+  - Use "remote://<name>/path" for a remote defined under storage.remotes in the config file.
+  - Use "pvc://<claim>/path" to write to a PersistentVolumeClaim. The claim is mounted in the job pod,
+    and the dump is written by the uploader container so it never leaves the cluster.
   - If the URL only contains a bucket name or if the path ends with "/", then filenames are autogenerated similarly to local dumps.
-  - Cloud config is loaded from the environment (similar to the aws and gcloud tools).
+  - Cloud config is loaded from the environment (similar to the aws and gcloud tools) unless a remote overrides it.
+  - Uploads can be encrypted with --sse and --sse-kms-key-id, and stored with --storage-class, --tags, and --metadata.
+    The namespace, dialect, and kubedb version are always added to the object metadata.
+  - Set --upload-from-cluster to upload from the job pod instead of this machine. Only progress is sent back.
+    Credentials are loaded from --upload-secret, the pod's --upload-service-account (workload identity), or the remote config.
+  - Set --detach to leave the dump running in the cluster after kubedb exits. The job name is printed,
+    and "kubedb jobs" can be used to check its status, follow it, or cancel it.
+
+Encryption:
+  - Set --recipients to encrypt the dump with age before it is written. Generated filenames will end with ".age".
+  - Recipients can be age public keys, ssh public keys, or paths to files containing them.
+  - Alternatively, set --passphrase or KUBEDB_PASSPHRASE to encrypt with a passphrase.
+  - Recipients can also be set in the config file.
 `
 }
@@ -0,0 +1,145 @@
+package attach
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/jobs"
+	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/tui"
+	"github.com/clevyr/kubedb/internal/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func New() *cobra.Command {
+	return &cobra.Command{
+		Use:   "attach job",
+		Short: "Follow the progress of a detached job",
+		Long: `Follow the progress of a detached job.
+
+Detaching with Ctrl-C leaves the job running.`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: jobs.CompleteNames,
+
+		RunE: run,
+	}
+}
+
+var (
+	ErrNotDetached = errors.New("job is not detached")
+	ErrJobFailed   = errors.New("job failed")
+	ErrOutput      = errors.New("output failed")
+)
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+
+	client, err := jobs.NewClient()
+	if err != nil {
+		return err
+	}
+
+	job, err := jobs.Get(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+	if !job.Detached {
+		return fmt.Errorf("%w: %s", ErrNotDetached, job.Name)
+	}
+
+	var pod corev1.Pod
+	for {
+		if pod, err = jobs.Pod(ctx, client, job.Name); err == nil && pod.Status.Phase != corev1.PodPending {
+			break
+		} else if err != nil && !errors.Is(err, jobs.ErrNoPod) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	slog.Info("Attached to job",
+		"namespace", client.Namespace,
+		"job", job.Name,
+		"pod", pod.Name,
+	)
+
+	bar := progressbar.New(os.Stderr, -1, "uploading", config.Global.Progress, config.Global.Spinner)
+	defer bar.Close()
+
+	stream, err := jobs.Logs(ctx, client, pod.Name, util.UploaderContainer, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	var result *dump.ClusterUploadResult
+	if err := jobs.Follow(stream, bar.Logger(), func(event *dump.ClusterUploadEvent) {
+		if event.Written != 0 {
+			_ = bar.Set64(event.Written)
+		}
+		if event.Result != nil {
+			result = event.Result
+		}
+	}); err != nil {
+		return err
+	}
+	_ = bar.Finish()
+
+	// The job status may lag behind the pod
+	for !job.Done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		if job, err = jobs.Get(ctx, client, job.Name); err != nil {
+			return err
+		}
+	}
+
+	return printResult(cmd, job, result)
+}
+
+func printResult(cmd *cobra.Command, job jobs.Job, result *dump.ClusterUploadResult) error {
+	t := tui.MinimalTable(nil).
+		Row("Job", job.Name).
+		Row("Status", string(job.Status)).
+		Row("Took", job.Duration(time.Now()).String())
+
+	var errs []error
+	if result != nil {
+		t.Row("Size", bytefmt.Encode(result.Size))
+		for _, out := range result.Outputs {
+			if out.Error != "" {
+				t.Row("Output", out.Path+" "+tui.ErrStyle(nil).Render(out.Error))
+				errs = append(errs, fmt.Errorf("%w: %s: %s", ErrOutput, out.Path, out.Error))
+				continue
+			}
+			t.Row("Output", out.Path)
+		}
+	}
+
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), "\n"+t.Render()); err != nil {
+		return err
+	}
+
+	if job.Status == jobs.StatusFailed {
+		errs = append([]error{fmt.Errorf("%w: %s", ErrJobFailed, job.Name)}, errs...)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package cancel
+
+import (
+	"log/slog"
+
+	"github.com/clevyr/kubedb/internal/jobs"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	return &cobra.Command{
+		Use:     "cancel job",
+		Aliases: []string{"stop", "rm"},
+		Short:   "Cancel a job",
+		Long: `Cancel a job.
+
+The job and its pod are deleted, along with any secret it created.`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: jobs.CompleteNames,
+
+		RunE: run,
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	client, err := jobs.NewClient()
+	if err != nil {
+		return err
+	}
+
+	if err := jobs.Cancel(cmd.Context(), client, args[0]); err != nil {
+		return err
+	}
+
+	slog.Info("Canceled job", "namespace", client.Namespace, "job", args[0])
+	return nil
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"github.com/clevyr/kubedb/cmd/jobs/attach"
+	"github.com/clevyr/kubedb/cmd/jobs/cancel"
+	"github.com/clevyr/kubedb/cmd/jobs/logs"
+	"github.com/clevyr/kubedb/cmd/jobs/status"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "jobs",
+		Aliases: []string{"job", "j"},
+		Short:   "Manage detached jobs",
+		Long: `Manage detached jobs.
+
+Jobs are started with "kubedb dump --detach" and keep running if this machine disconnects.
+They are found using the app.kubernetes.io/name=kubedb label in the current namespace.`,
+		GroupID: "rw",
+
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	cmd.AddCommand(
+		status.New(),
+		logs.New(),
+		attach.New(),
+		cancel.New(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"gabe565.com/utils/must"
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/completion"
+	"github.com/clevyr/kubedb/internal/jobs"
+	"github.com/clevyr/kubedb/internal/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const flagFollow = "follow"
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs job",
+		Short: "Print the logs of a job",
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: jobs.CompleteNames,
+
+		RunE: run,
+	}
+
+	cmd.Flags().BoolP(flagFollow, "f", false, "Stream logs until the job exits")
+	must.Must(cmd.RegisterFlagCompletionFunc(flagFollow, completion.BoolCompletion))
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	follow := must.Must2(cmd.Flags().GetBool(flagFollow))
+
+	client, err := jobs.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pod, err := jobs.Pod(cmd.Context(), client, args[0])
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var group errgroup.Group
+	for _, container := range pod.Spec.Containers {
+		group.Go(func() error {
+			stream, err := jobs.Logs(cmd.Context(), client, pod.Name, container.Name, follow)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = stream.Close()
+			}()
+
+			w := &prefixWriter{w: cmd.OutOrStdout(), mu: &mu, prefix: "[" + container.Name + "] "}
+			if container.Name == util.UploaderContainer {
+				// Progress events are shown by "kubedb jobs attach"
+				return jobs.Follow(stream, w, func(*dump.ClusterUploadEvent) {})
+			}
+			scanner := bufio.NewScanner(stream)
+			for scanner.Scan() {
+				if _, err := w.Write(append(scanner.Bytes(), '\n')); err != nil {
+					return err
+				}
+			}
+			return scanner.Err()
+		})
+	}
+	return group.Wait()
+}
+
+// prefixWriter writes complete lines from several containers without interleaving them.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
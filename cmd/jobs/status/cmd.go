@@ -0,0 +1,81 @@
+package status
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/clevyr/kubedb/internal/jobs"
+	"github.com/clevyr/kubedb/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	return &cobra.Command{
+		Use:     "status [job]",
+		Aliases: []string{"list", "ls"},
+		Short:   "Show the status of jobs",
+
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: jobs.CompleteNames,
+
+		RunE: run,
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	client, err := jobs.NewClient()
+	if err != nil {
+		return err
+	}
+
+	var list []jobs.Job
+	if len(args) != 0 {
+		job, err := jobs.Get(cmd.Context(), client, args[0])
+		if err != nil {
+			return err
+		}
+		list = []jobs.Job{job}
+	} else if list, err = jobs.List(cmd.Context(), client); err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		slog.Info("No jobs found", "namespace", client.Namespace)
+		return nil
+	}
+
+	now := time.Now()
+	t := tui.ListTable(nil, "Name", "Type", "Status", "Started", "Duration", "Written", "Output")
+	for _, job := range list {
+		var written string
+		if job.Detached {
+			if pod, err := jobs.Pod(cmd.Context(), client, job.Name); err == nil {
+				if progress, err := jobs.GetProgress(cmd.Context(), client, pod); err == nil && progress.Written != 0 {
+					written = bytefmt.Encode(progress.Written)
+				}
+			}
+		}
+
+		status := string(job.Status)
+		if job.Status == jobs.StatusFailed {
+			status = tui.ErrStyle(nil).Render(status)
+		}
+
+		t.Row(
+			job.Name,
+			job.Component,
+			status,
+			job.Started.Local().Format(time.DateTime),
+			job.Duration(now).String(),
+			written,
+			strings.Join(job.Outputs, "\n"),
+		)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), t.Render())
+	return err
+}
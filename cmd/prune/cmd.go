@@ -0,0 +1,141 @@
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"gabe565.com/utils/must"
+	"gabe565.com/utils/termx"
+	"github.com/clevyr/kubedb/internal/actions"
+	"github.com/clevyr/kubedb/internal/actions/prune"
+	"github.com/clevyr/kubedb/internal/completion"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/config/flags"
+	"github.com/clevyr/kubedb/internal/consts"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune [dir | bucket URI]",
+		Short: "Delete old dumps using a retention policy",
+		Long:  newDescription(),
+
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: validArgs,
+		GroupID:           "rw",
+
+		PreRunE: preRun,
+		RunE:    run,
+	}
+
+	flags.FilenameTemplate(cmd)
+	flags.PVCAppPod(cmd)
+	cmd.Flags().Int(consts.FlagKeepLast, 0, "Keep the newest n dumps")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagKeepLast, cobra.NoFileCompletions))
+	cmd.Flags().Int(consts.FlagKeepDaily, 7, "Keep the newest dump for each of the last n days")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagKeepDaily, cobra.NoFileCompletions))
+	cmd.Flags().Int(consts.FlagKeepWeekly, 4, "Keep the newest dump for each of the last n weeks")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagKeepWeekly, cobra.NoFileCompletions))
+	cmd.Flags().Int(consts.FlagKeepMonthly, 12, "Keep the newest dump for each of the last n months")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagKeepMonthly, cobra.NoFileCompletions))
+	cmd.Flags().Bool(consts.FlagDryRun, false, "Print which dumps would be deleted without deleting them")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagDryRun, completion.BoolCompletion))
+	cmd.Flags().BoolP(consts.FlagForce, "f", false, "Do not prompt before deleting")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagForce, completion.BoolCompletion))
+
+	return cmd
+}
+
+func preRun(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	action := &prune.Prune{Prune: conftypes.Prune{Global: config.Global}}
+
+	if err := config.Unmarshal(cmd, "prune", &action); err != nil {
+		return err
+	}
+	action.Dir = "."
+	if len(args) > 0 {
+		action.Dir = args[0]
+	}
+
+	cmd.SetContext(actions.NewContext(cmd.Context(), action))
+	return nil
+}
+
+func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if storage.IsCloud(toComplete) {
+		if err := completion.LoadConfig(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		u, err := url.Parse(toComplete)
+		if err != nil {
+			slog.Error("Failed to parse URL", "error", err)
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		if u.Host == "" || u.Path == "" {
+			return storage.CompleteBuckets(u)
+		}
+		return storage.CompleteObjects(u, nil, true)
+	}
+
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+var (
+	ErrPruneCanceled = errors.New("prune canceled")
+	ErrPruneRefused  = errors.New("refusing to delete dumps non-interactively without the --force flag")
+)
+
+func run(cmd *cobra.Command, _ []string) error {
+	action := actions.FromContext[*prune.Prune](cmd.Context())
+
+	decisions, err := action.Plan(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if len(decisions) == 0 {
+		slog.Info("No dumps found", "dir", action.Dir)
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), action.Table(nil, decisions).Render())
+
+	var pending bool
+	for _, d := range decisions {
+		if !d.Keep() {
+			pending = true
+			break
+		}
+	}
+
+	switch {
+	case !pending:
+		slog.Info("Nothing to prune")
+		return nil
+	case action.DryRun:
+		return nil
+	case action.Force:
+	case termx.IsTerminal(cmd.InOrStdin()):
+		if response, err := action.Confirm(decisions); err != nil {
+			return err
+		} else if !response {
+			return ErrPruneCanceled
+		}
+	default:
+		return ErrPruneRefused
+	}
+
+	return action.Run(cmd.Context(), decisions)
+}
@@ -0,0 +1,18 @@
+package prune
+
+func newDescription() string {
+	return `Delete old dumps using a retention policy.
+
+Dumps are matched by their generated filenames, which contain the namespace, database, and date.
+Each keep rule is applied separately to every namespace and database.
+A dump is deleted only if no rule keeps it.
+
+Directory:
+  - Defaults to the current directory.
+  - Use "s3://", "gs://", "b2://", "azblob://", "sftp://", or "remote://" to prune a cloud prefix.
+
+Keep Rules:
+  - --keep-last keeps the newest n dumps.
+  - --keep-daily, --keep-weekly, and --keep-monthly keep the newest dump for each of the last n days, weeks, or months.
+  - Use --dry-run to preview which dumps would be deleted.`
+}
@@ -20,6 +20,10 @@ import (
 	"github.com/clevyr/kubedb/internal/config/flags"
 	"github.com/clevyr/kubedb/internal/consts"
 	"github.com/clevyr/kubedb/internal/database"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
+	"github.com/clevyr/kubedb/internal/ratelimit"
+	"github.com/clevyr/kubedb/internal/split"
 	"github.com/clevyr/kubedb/internal/storage"
 	"github.com/clevyr/kubedb/internal/tui"
 	"github.com/clevyr/kubedb/internal/util"
@@ -54,15 +58,31 @@ func New() *cobra.Command {
 	flags.NoOwner(cmd)
 	flags.Quiet(cmd)
 	flags.RemoteGzip(cmd)
+	flags.RemoteZstd(cmd)
+	flags.LimitRate(cmd)
 	flags.Analyze(cmd)
 	flags.HaltOnError(cmd)
 	flags.Spinner(cmd)
 	flags.Opts(cmd)
+	flags.Identities(cmd)
+	flags.Passphrase(cmd)
 	flags.Progress(cmd)
+	flags.FilenameTemplate(cmd)
+	flags.PVCAppPod(cmd)
 	cmd.Flags().StringP(consts.FlagInput, "i", "", "Input file path (can also be set using a positional arg)")
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagInput, validArgs))
 	cmd.Flags().BoolP(consts.FlagForce, "f", false, "Do not prompt before restore")
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagForce, completion.BoolCompletion))
+	cmd.Flags().Bool(consts.FlagLatest, false, "Restore the newest dump in the input directory or bucket prefix")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagLatest, completion.BoolCompletion))
+	cmd.Flags().String(consts.FlagSourceNamespace, "", "Namespace to find the latest dump for (default current namespace)")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSourceNamespace, cobra.NoFileCompletions))
+	cmd.Flags().String(consts.FlagSourceDBName, "", "Database to find the latest dump for (default any)")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSourceDBName, cobra.NoFileCompletions))
+	cmd.Flags().Bool(consts.FlagStaged, false, "Upload the dump to the job pod before restoring it, so dropped connections can resume and checksums are verified before anything is restored")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagStaged, completion.BoolCompletion))
+	cmd.Flags().IntP(consts.FlagJobs, "j", 0, "Number of parallel pg_restore jobs for custom format dumps. Requires --staged")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagJobs, cobra.NoFileCompletions))
 
 	return cmd
 }
@@ -76,6 +96,13 @@ func preRun(cmd *cobra.Command, args []string) error {
 	if err := util.DefaultSetup(cmd, action.Global); err != nil {
 		return err
 	}
+	if !cmd.Flags().Lookup(consts.FlagLimitRate).Changed {
+		limit, err := ratelimit.ForNamespace(action.NamespaceLimitRates, action.Namespace, action.LimitRate)
+		if err != nil {
+			return err
+		}
+		action.LimitRate = limit
+	}
 	if len(args) > 0 {
 		action.Input = args[0]
 	}
@@ -114,7 +141,13 @@ func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string,
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	formats := db.Formats()
+	formats := slices.Collect(maps.Values(db.Formats()))
+	for _, ext := range formats {
+		formats = append(formats, ext+encryption.Ext)
+	}
+	for _, ext := range formats {
+		formats = append(formats, ext+split.Ext(1))
+	}
 
 	if storage.IsCloud(toComplete) {
 		u, err := url.Parse(toComplete)
@@ -126,7 +159,11 @@ func validArgs(cmd *cobra.Command, args []string, toComplete string) ([]string,
 		if u.Host == "" || u.Path == "" {
 			return storage.CompleteBuckets(u)
 		}
-		return storage.CompleteObjects(u, slices.Collect(maps.Values(formats)), false)
+		return storage.CompleteObjects(u, formats, action.Latest)
+	}
+
+	if action.Latest {
+		return nil, cobra.ShellCompDirectiveFilterDirs
 	}
 
 	exts := make([]string, 0, len(formats))
@@ -144,8 +181,17 @@ var (
 func run(cmd *cobra.Command, args []string) error {
 	action := actions.FromContext[*restore.Restore](cmd.Context())
 
+	if action.Latest && !config.IsCompletion {
+		if action.Input == "" {
+			action.Input = "."
+		}
+		if err := action.FindLatest(cmd.Context()); err != nil {
+			return err
+		}
+	}
+
 	switch {
-	case action.Input == "-", storage.IsCloud(action.Input), config.IsCompletion:
+	case action.Input == "-", storage.IsCloud(action.Input), storage.IsHTTP(action.Input), config.IsCompletion:
 	case action.Input == "":
 		if termx.IsTerminal(cmd.InOrStdin()) {
 			db, ok := action.Dialect.(conftypes.DBRestorer)
@@ -153,6 +199,14 @@ func run(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("%w: %s", util.ErrNoRestore, action.Dialect.Name())
 			}
 
+			allowed := slices.Collect(maps.Values(db.Formats()))
+			for _, ext := range allowed {
+				allowed = append(allowed, ext+encryption.Ext)
+			}
+			for _, ext := range allowed {
+				allowed = append(allowed, ext+split.Ext(1))
+			}
+
 			wd, err := os.Getwd()
 			if err != nil {
 				return err
@@ -166,7 +220,7 @@ func run(cmd *cobra.Command, args []string) error {
 					ShowSize(true).
 					ShowPermissions(false).
 					Height(15).
-					AllowedTypes(slices.Collect(maps.Values(db.Formats()))).
+					AllowedTypes(allowed).
 					Value(&action.Input),
 			))
 
@@ -192,6 +246,30 @@ func run(cmd *cobra.Command, args []string) error {
 		action.Format = database.DetectFormat(db, action.Input)
 	}
 
+	if storage.IsPVC(action.Input) {
+		if !action.CreateJob {
+			return restore.ErrPVCJob
+		}
+		claims, err := storage.PVCClaims([]string{action.Input})
+		if err != nil {
+			return err
+		}
+		action.Claims = claims
+	}
+
+	if err := action.ValidateStaged(); err != nil {
+		return err
+	}
+	if action.Staged {
+		switch {
+		case action.Jobs > 1 && action.Format != sqlformat.Custom:
+			slog.Warn("Parallel jobs are only used for custom format dumps")
+		case action.Jobs > 1 && action.SingleTransaction:
+			slog.Warn("Parallel jobs cannot run in a single transaction, so --single-transaction is ignored")
+		}
+		action.Stage = true
+	}
+
 	switch {
 	case action.Force:
 	case termx.IsTerminal(cmd.InOrStdin()):
@@ -18,9 +18,30 @@ Supported Databases:
 File Path:
   - Raw sql file. Typically with a ".sql" file extension
   - Gzipped sql file. Typically with a ".sql.gz" file extension
+  - Zstd compressed sql file. Typically with a ".sql.zst" file extension
   - For Postgres: custom dump file. Typically with a ".dmp" file extension
+  - If a "<file>.json" manifest exists next to the file, the checksum is verified while restoring.
+    A mismatch is only found at the end of the file and aborts the restore, but statements which
+    already ran are not undone. Use --staged to verify the whole file before anything is restored.
+  - Split dumps are restored by passing the first volume ("<file>.part001") or "<file>".
+    Every volume is read in order, and the restore fails if any are missing.
+  - With --latest, the path is a directory or bucket prefix, and the newest dump is restored.
+    Use --source-namespace and --source-dbname to restore a dump from another namespace or database.
+  - With --staged, the file is uploaded to the job pod before it is restored.
+    If the connection drops, the upload resumes where it left off.
+    For Postgres custom dumps, --jobs runs pg_restore in parallel against the staged file.
 
 Cloud Download:
-  - Use "s3://" for S3, "gs://" for GCS, or "b2://" for Backblaze B2.
-  - Cloud config is loaded from the environment (similar to the aws and gcloud tools).`
+  - Use "s3://" for S3, "gs://" for GCS, "b2://" for Backblaze B2, or "azblob://" for Azure Blob Storage.
+  - Use "sftp://user@host/path" to download over SSH. Keys are loaded from ssh-agent or ~/.ssh, and hosts are verified with known_hosts.
+  - Use "remote://<name>/path" for a remote defined under storage.remotes in the config file.
+  - Use "pvc://<claim>/path" to restore from a PersistentVolumeClaim. The claim is mounted in the job pod,
+    so the dump is read inside the cluster. Encrypted dumps are not supported.
+  - Use "https://" or "http://" to download from a URL, such as a presigned link. Request headers can be set per host in the config file.
+  - Cloud config is loaded from the environment (similar to the aws and gcloud tools) unless a remote overrides it.
+
+Encryption:
+  - Files encrypted with age are decrypted automatically.
+  - Set --identities to age secret keys, or paths to age or ssh identity files. Identities can also be set in the config file.
+  - Set --passphrase or KUBEDB_PASSPHRASE for files encrypted with a passphrase.`
 }
@@ -0,0 +1,504 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
+	"github.com/clevyr/kubedb/internal/finalizer"
+	"github.com/clevyr/kubedb/internal/github"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/util"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrClusterUploadJob    = errors.New("uploading from the cluster requires a job pod")
+	ErrClusterUploadOutput = errors.New("uploading from the cluster requires an s3, gs, b2, azblob, remote, or pvc output")
+	ErrUploadAborted       = errors.New("upload aborted")
+	ErrFIFOTimeout         = errors.New("timed out waiting for the database client")
+)
+
+// clusterUploadFIFO connects the database client to the uploader container.
+const clusterUploadFIFO = util.UploadDir + "/dump"
+
+// clusterUploadRequest is sent to the uploader over stdin.
+type clusterUploadRequest struct {
+	Input      string                      `json:"input"`
+	Output     []string                    `json:"output"`
+	Format     sqlformat.Format            `json:"format"`
+	Compressed bool                        `json:"compressed"`
+	Recipients []string                    `json:"recipients,omitempty"`
+	Passphrase string                      `json:"passphrase,omitempty"`
+	SplitSize  int64                       `json:"splitSize,omitempty"`
+	Put        storage.PutOptions          `json:"put"`
+	Remotes    map[string]conftypes.Remote `json:"remotes,omitempty"`
+	Manifest   manifest.Manifest           `json:"manifest"`
+}
+
+// clusterUploadCommit is sent once the database client succeeds.
+// If stdin is closed without it, the uploads are canceled.
+type clusterUploadCommit struct {
+	Commit bool `json:"commit"`
+}
+
+// ClusterUploadEvent is written to stdout by the uploader.
+type ClusterUploadEvent struct {
+	Written int64                `json:"written,omitempty"`
+	Result  *ClusterUploadResult `json:"result,omitempty"`
+}
+
+// ClusterUploadResult is sent once the uploads are complete.
+type ClusterUploadResult struct {
+	Size    int64                 `json:"size"`
+	Outputs []ClusterUploadOutput `json:"outputs"`
+}
+
+// ClusterUploadOutput is the result of a single output.
+type ClusterUploadOutput struct {
+	Path  string `json:"path"`
+	Parts int    `json:"parts,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ValidateClusterUpload checks that every output can be written from the job pod.
+func (action Dump) ValidateClusterUpload() error {
+	if !action.CreateJob {
+		return ErrClusterUploadJob
+	}
+	for _, out := range action.Output {
+		if !storage.IsCloud(out) || storage.IsSFTP(out) {
+			return fmt.Errorf("%w: %s", ErrClusterUploadOutput, out)
+		}
+		if storage.IsRemote(out) {
+			remote, err := action.remote(out)
+			if err != nil {
+				return err
+			}
+			if storage.IsSFTP(remote.URL) {
+				return fmt.Errorf("%w: %s", ErrClusterUploadOutput, out)
+			}
+		}
+	}
+	return nil
+}
+
+// ClusterUploadPorts returns the TCP ports the uploader needs to reach the outputs.
+// Endpoints set in the upload secret cannot be seen here, so buckets default to HTTPS.
+func (action Dump) ClusterUploadPorts() ([]int32, error) {
+	var ports []int32
+	for _, out := range action.Output {
+		var port int32
+		switch {
+		case storage.IsPVC(out):
+			continue
+		case storage.IsRemote(out):
+			remote, err := action.remote(out)
+			if err != nil {
+				return nil, err
+			}
+			if port, err = endpointPort(remote.Endpoint); err != nil {
+				return nil, err
+			}
+		default:
+			port = 443
+		}
+		if !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	slices.Sort(ports)
+	return ports, nil
+}
+
+func (action Dump) remote(out string) (conftypes.Remote, error) {
+	u, err := url.Parse(out)
+	if err != nil {
+		return conftypes.Remote{}, err
+	}
+	remote, ok := action.Storage.Remotes[u.Host]
+	if !ok {
+		return conftypes.Remote{}, fmt.Errorf("%w: %s", storage.ErrUnknownRemote, u.Host)
+	}
+	return remote, nil
+}
+
+// endpointPort returns the port of a storage endpoint. Endpoints without a scheme use HTTPS.
+func endpointPort(endpoint string) (int32, error) {
+	if endpoint == "" {
+		return 443, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	if p := u.Port(); p != "" {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return 0, err
+		}
+		return int32(port), nil
+	}
+	if u.Scheme == "http" {
+		return 80, nil
+	}
+	return 443, nil
+}
+
+func (action Dump) clusterUploadRequest(splitSize int64, putOpts storage.PutOptions) (*clusterUploadRequest, error) {
+	recipients, err := encryption.ExpandRecipients(action.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	// Claims are mounted in the job pod, so write to them directly
+	outputs := slices.Clone(action.Output)
+	for i, out := range outputs {
+		if storage.IsPVC(out) {
+			if outputs[i], err = storage.PVCPath(out); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Only send the remotes which are used, since they may contain credentials
+	var remotes map[string]conftypes.Remote
+	for _, out := range action.Output {
+		if !storage.IsRemote(out) {
+			continue
+		}
+		u, err := url.Parse(out)
+		if err != nil {
+			return nil, err
+		}
+		remote, ok := action.Storage.Remotes[u.Host]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", storage.ErrUnknownRemote, u.Host)
+		}
+		if remotes == nil {
+			remotes = make(map[string]conftypes.Remote)
+		}
+		remotes[u.Host] = remote
+	}
+
+	return &clusterUploadRequest{
+		Input:      clusterUploadFIFO,
+		Output:     outputs,
+		Format:     action.Format,
+		Compressed: action.remoteCompression(),
+		Recipients: recipients,
+		Passphrase: action.Passphrase,
+		SplitSize:  splitSize,
+		Put:        putOpts,
+		Remotes:    remotes,
+		Manifest:   action.manifest(),
+	}, nil
+}
+
+// runInCluster streams the dump to an uploader container in the job pod.
+// Only progress and the result are sent back to this machine.
+//
+//nolint:funlen
+func (action Dump) runInCluster(ctx context.Context, splitSize int64, putOpts storage.PutOptions) error {
+	errGroup, ctx := errgroup.WithContext(ctx)
+
+	req, err := action.clusterUploadRequest(splitSize, putOpts)
+	if err != nil {
+		return err
+	}
+
+	outs := make(outputs, 0, len(action.Output))
+	for _, path := range action.Output {
+		outs = append(outs, &output{Path: path})
+	}
+
+	slog.Info("Exporting database",
+		"namespace", action.Client.Namespace,
+		"pod", action.DBPod.Name,
+		"file", strings.Join(action.Output, ", "),
+		"uploader", action.JobPod.Name,
+	)
+
+	if err := github.SetOutput("filename", action.Output[0]); err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	bar := progressbar.New(os.Stderr, -1, "uploading", action.Progress, action.Spinner)
+	defer bar.Close()
+
+	if err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+		Pod:       action.JobPod,
+		Container: util.UploaderContainer,
+		Cmd:       command.NewBuilder("mkfifo", "-m", "666", clusterUploadFIFO).String(),
+		Stderr:    bar.Logger(),
+	}); err != nil {
+		return err
+	}
+
+	stdinR, stdinW := io.Pipe()
+	eventsR, eventsW := io.Pipe()
+
+	errGroup.Go(func() error {
+		// Begin database export
+		if err := json.NewEncoder(stdinW).Encode(req); err != nil {
+			return err
+		}
+
+		cmd, err := action.buildCommand()
+		if err != nil {
+			_ = stdinW.CloseWithError(err)
+			return err
+		}
+		cmd.Push(command.Raw(">"), clusterUploadFIFO)
+
+		if err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+			Pod:         action.JobPod,
+			Container:   util.JobContainer,
+			Cmd:         cmd.String(),
+			Stderr:      bar.Logger(),
+			DisablePing: true,
+		}); err != nil {
+			_ = stdinW.CloseWithError(err)
+			return err
+		}
+
+		// The export succeeded, so the uploads can be completed
+		err = json.NewEncoder(stdinW).Encode(clusterUploadCommit{Commit: true})
+		_ = stdinW.Close()
+		return err
+	})
+
+	errGroup.Go(func() error {
+		// Begin upload
+		err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+			Pod:         action.JobPod,
+			Container:   util.UploaderContainer,
+			Cmd:         command.NewBuilder("kubedb", "cluster-upload").String(),
+			Stdin:       stdinR,
+			Stdout:      eventsW,
+			Stderr:      bar.Logger(),
+			DisablePing: true,
+		})
+		_ = eventsW.CloseWithError(err)
+		return err
+	})
+
+	var written atomic.Int64
+	var result *ClusterUploadResult
+	errGroup.Go(func() error {
+		// Follow upload progress
+		dec := json.NewDecoder(eventsR)
+		for {
+			var event ClusterUploadEvent
+			if err := dec.Decode(&event); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				_ = eventsR.CloseWithError(err)
+				return err
+			}
+			if event.Written != 0 {
+				_ = bar.Set64(event.Written)
+			}
+			if event.Result != nil {
+				written.Store(event.Result.Size)
+				result = event.Result
+			}
+		}
+	})
+
+	finalizer.Add(func(err error) {
+		action.printSummary(err, outs, time.Since(startTime).Truncate(10*time.Millisecond), written.Load())
+	})
+
+	err = errGroup.Wait()
+	if result != nil {
+		for i, o := range result.Outputs {
+			if i >= len(outs) {
+				break
+			}
+			outs[i].parts = o.Parts
+			if o.Error != "" {
+				outs[i].Err = errors.New(o.Error) //nolint:err113
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return ErrUploadAborted
+	}
+
+	_ = bar.Finish()
+
+	return action.complete(ctx, outs, time.Since(startTime), written.Load())
+}
+
+// ClusterUpload runs in the job pod's uploader container.
+// It reads a request from r, writes the dump from the request's input to each output,
+// and reports progress to w.
+func ClusterUpload(ctx context.Context, r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	var req clusterUploadRequest
+	if err := dec.Decode(&req); err != nil {
+		return err
+	}
+	config.Global.Storage.Remotes = req.Remotes
+
+	st := stream{Format: req.Format, Compressed: req.Compressed}
+	if encryption.Enabled(req.Recipients, req.Passphrase) {
+		var err error
+		if st.Recipients, err = encryption.ParseRecipients(req.Recipients, req.Passphrase); err != nil {
+			return err
+		}
+	}
+
+	outs := make(outputs, 0, len(req.Output))
+	defer func() {
+		for _, o := range outs {
+			o.cleanup(nil)
+		}
+	}()
+	for _, path := range req.Output {
+		o, err := openOutput(ctx, path, req.SplitSize, req.Put)
+		if err != nil {
+			return err
+		}
+		outs = append(outs, o)
+	}
+
+	// The commit is read in the background, so an aborted export also stops waiting for the FIFO
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	committed := make(chan bool, 1)
+	go func() {
+		var commit clusterUploadCommit
+		ok := dec.Decode(&commit) == nil && commit.Commit
+		if !ok {
+			cancel(ErrUploadAborted)
+		}
+		committed <- ok
+	}()
+
+	f, err := openFIFO(ctx, req.Input, FIFOTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	events := json.NewEncoder(w)
+	var progress counter
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = events.Encode(ClusterUploadEvent{Written: progress.Load()})
+			}
+		}
+	})
+
+	digest := manifest.NewDigest()
+	n, err := st.write(f, outs, digest, &progress)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	// Wait for the database client to succeed before completing the uploads
+	if !<-committed {
+		return ErrUploadAborted
+	}
+
+	_ = outs.finish()
+	m := req.Manifest
+	m.SHA256 = digest.Sum()
+	m.Size = digest.Size()
+	outs.writeManifests(ctx, m, req.Put)
+
+	result := &ClusterUploadResult{Size: n, Outputs: make([]ClusterUploadOutput, 0, len(outs))}
+	for _, o := range outs {
+		out := ClusterUploadOutput{Path: o.Path, Parts: o.Parts()}
+		if o.Err != nil {
+			out.Error = o.Err.Error()
+		}
+		result.Outputs = append(result.Outputs, out)
+	}
+	return events.Encode(ClusterUploadEvent{Written: progress.Load(), Result: result})
+}
+
+// FIFOTimeout is how long the uploader waits for the database client to open the FIFO.
+var FIFOTimeout = 5 * time.Minute //nolint:gochecknoglobals
+
+// openFIFO opens a FIFO for reading. Unlike os.Open, it gives up once ctx is done or the timeout passes.
+func openFIFO(ctx context.Context, path string, timeout time.Duration) (*os.File, error) {
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrFIFOTimeout)
+	defer cancel()
+
+	type result struct {
+		f   *os.File
+		err error
+	}
+	opened := make(chan result, 1)
+	go func() {
+		f, err := os.Open(path)
+		opened <- result{f, err}
+	}()
+
+	select {
+	case r := <-opened:
+		return r.f, r.err
+	case <-ctx.Done():
+		// Connect as a writer to unblock the pending open
+		if w, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			_ = w.Close()
+			if r := <-opened; r.f != nil {
+				_ = r.f.Close()
+			}
+		}
+		return nil, context.Cause(ctx)
+	}
+}
+
+// counter counts the bytes written to it.
+type counter struct {
+	atomic.Int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	c.Add(int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,233 @@
+package dump
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/postgres"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump_ValidateClusterUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		createJob bool
+		output    []string
+		wantErr   error
+	}{
+		{"s3", true, []string{"s3://bucket/a.sql.gz"}, nil},
+		{"remote", true, []string{"remote://prod/a.sql.gz", "gs://bucket/a.sql.gz"}, nil},
+		{"remote sftp", true, []string{"remote://archive/a.sql.gz"}, ErrClusterUploadOutput},
+		{"unknown remote", true, []string{"remote://missing/a.sql.gz"}, storage.ErrUnknownRemote},
+		{"pvc", true, []string{"pvc://backups/a.sql.gz"}, nil},
+		{"no job", false, []string{"s3://bucket/a.sql.gz"}, ErrClusterUploadJob},
+		{"local", true, []string{"s3://bucket/a.sql.gz", "a.sql.gz"}, ErrClusterUploadOutput},
+		{"stdout", true, []string{"-"}, ErrClusterUploadOutput},
+		{"sftp", true, []string{"sftp://host/a.sql.gz"}, ErrClusterUploadOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := Dump{Dump: conftypes.Dump{
+				Global: &conftypes.Global{
+					CreateJob: tt.createJob,
+					Storage: conftypes.Storage{Remotes: map[string]conftypes.Remote{
+						"prod":    {URL: "s3://prod"},
+						"archive": {URL: "sftp://example.com/archive"},
+					}},
+				},
+				Output: tt.output,
+			}}
+			err := action.ValidateClusterUpload()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDump_ClusterUploadPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  []string
+		want    []int32
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"bucket", []string{"s3://bucket/a.sql.gz", "gs://bucket/a.sql.gz"}, []int32{443}, require.NoError},
+		{"pvc", []string{"pvc://backups/a.sql.gz"}, nil, require.NoError},
+		{"remote", []string{"remote://prod/a.sql.gz"}, []int32{443}, require.NoError},
+		{"remote endpoint", []string{"remote://minio/a.sql.gz", "s3://bucket/a.sql.gz"}, []int32{443, 9000}, require.NoError},
+		{"remote http endpoint", []string{"remote://local/a.sql.gz"}, []int32{80}, require.NoError},
+		{"unknown remote", []string{"remote://missing/a.sql.gz"}, nil, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := Dump{Dump: conftypes.Dump{
+				Global: &conftypes.Global{
+					Storage: conftypes.Storage{Remotes: map[string]conftypes.Remote{
+						"prod":  {URL: "s3://prod"},
+						"minio": {URL: "s3://backups", Endpoint: "minio.example.com:9000"},
+						"local": {URL: "s3://backups", Endpoint: "http://minio.example.com"},
+					}},
+				},
+				Output: tt.output,
+			}}
+			got, err := action.ClusterUploadPorts()
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDump_clusterUploadRequest(t *testing.T) {
+	action := Dump{Dump: conftypes.Dump{
+		Global: &conftypes.Global{
+			Dialect: postgres.Postgres{},
+			Storage: conftypes.Storage{Remotes: map[string]conftypes.Remote{
+				"prod":    {URL: "s3://prod"},
+				"staging": {URL: "s3://staging"},
+			}},
+		},
+		Output: []string{"remote://prod/a.sql.gz", "s3://bucket/a.sql.gz", "pvc://backups/db/a.sql.gz"},
+		Format: sqlformat.Gzip,
+	}}
+
+	req, err := action.clusterUploadRequest(0, storage.PutOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]conftypes.Remote{"prod": {URL: "s3://prod"}}, req.Remotes)
+	assert.Equal(t, []string{"remote://prod/a.sql.gz", "s3://bucket/a.sql.gz", "/kubedb-pvc/backups/db/a.sql.gz"}, req.Output)
+	assert.Equal(t, "pvc://backups/db/a.sql.gz", action.Output[2])
+
+	action.Output = []string{"remote://missing/a.sql.gz"}
+	_, err = action.clusterUploadRequest(0, storage.PutOptions{})
+	require.ErrorIs(t, err, storage.ErrUnknownRemote)
+}
+
+func clusterUploadStdin(t *testing.T, req clusterUploadRequest, commit bool) *bytes.Buffer {
+	var stdin bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	require.NoError(t, enc.Encode(&req))
+	if commit {
+		require.NoError(t, enc.Encode(clusterUploadCommit{Commit: true}))
+	}
+	return &stdin
+}
+
+func TestClusterUpload(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	require.NoError(t, os.WriteFile(input, []byte("SELECT 1;"), 0o600))
+	out := filepath.Join(dir, "out", "a.sql")
+
+	stdin := clusterUploadStdin(t, clusterUploadRequest{
+		Input:    input,
+		Output:   []string{out},
+		Format:   sqlformat.Plain,
+		Manifest: manifest.Manifest{Namespace: "test", Format: sqlformat.Plain},
+	}, true)
+
+	var stdout bytes.Buffer
+	require.NoError(t, ClusterUpload(t.Context(), stdin, &stdout))
+
+	got, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1;", string(got))
+
+	m, err := manifest.Read(t.Context(), nil, out)
+	require.NoError(t, err)
+	assert.Equal(t, "test", m.Namespace)
+	assert.EqualValues(t, 9, m.Size)
+
+	var event ClusterUploadEvent
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		require.NoError(t, dec.Decode(&event))
+	}
+	require.NotNil(t, event.Result)
+	assert.EqualValues(t, 9, event.Result.Size)
+	assert.Equal(t, []ClusterUploadOutput{{Path: out}}, event.Result.Outputs)
+}
+
+func TestClusterUpload_Aborted(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	require.NoError(t, os.WriteFile(input, []byte("SELECT"), 0o600))
+	out := filepath.Join(dir, "a.sql")
+
+	stdin := clusterUploadStdin(t, clusterUploadRequest{
+		Input:    input,
+		Output:   []string{out},
+		Format:   sqlformat.Plain,
+		Manifest: manifest.Manifest{Format: sqlformat.Plain},
+	}, false)
+
+	require.ErrorIs(t, ClusterUpload(t.Context(), stdin, &bytes.Buffer{}), ErrUploadAborted)
+	assert.NoFileExists(t, out)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func mkfifo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("mkfifo"); err != nil {
+		t.Skip("mkfifo not found")
+	}
+	path := filepath.Join(t.TempDir(), "fifo")
+	require.NoError(t, exec.Command("mkfifo", path).Run())
+	return path
+}
+
+func TestClusterUpload_AbortedBeforeOpen(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "a.sql")
+
+	// The database client never opens the FIFO
+	stdin := clusterUploadStdin(t, clusterUploadRequest{
+		Input:    mkfifo(t),
+		Output:   []string{out},
+		Format:   sqlformat.Plain,
+		Manifest: manifest.Manifest{Format: sqlformat.Plain},
+	}, false)
+
+	require.ErrorIs(t, ClusterUpload(t.Context(), stdin, &bytes.Buffer{}), ErrUploadAborted)
+	assert.NoFileExists(t, out)
+}
+
+func Test_openFIFO(t *testing.T) {
+	t.Run("opened", func(t *testing.T) {
+		path := mkfifo(t)
+		go func() {
+			if w, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+				_, _ = w.WriteString("SELECT 1;")
+				_ = w.Close()
+			}
+		}()
+
+		f, err := openFIFO(t.Context(), path, time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = f.Close()
+		})
+		got, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1;", string(got))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := openFIFO(t.Context(), mkfifo(t), 10*time.Millisecond)
+		require.ErrorIs(t, err, ErrFIFOTimeout)
+	})
+}
@@ -0,0 +1,149 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/util"
+)
+
+const (
+	clusterUploadRequestEnv = "KUBEDB_UPLOAD_REQUEST"
+	dumpCommandEnv          = "KUBEDB_DUMP_COMMAND"
+	// clusterUploadCommitFile is written by the job container once the database client exits
+	clusterUploadCommitFile = util.UploadDir + "/commit"
+)
+
+// Detached returns a job which dumps and uploads the database without this machine connected.
+func (action Dump) Detached() (util.Detached, error) {
+	splitSize, err := util.ParseSize(action.SplitSize)
+	if err != nil {
+		return util.Detached{}, err
+	}
+
+	putOpts := action.putOptions()
+	if err := putOpts.Validate(); err != nil {
+		return util.Detached{}, err
+	}
+
+	req, err := action.clusterUploadRequest(splitSize, putOpts)
+	if err != nil {
+		return util.Detached{}, err
+	}
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		return util.Detached{}, err
+	}
+
+	// The job pod connects to the database pod directly
+	global := *action.Global
+	global.Host = action.DBPod.Status.PodIP
+	action.Global = &global
+	dumpCmd, err := action.buildCommand()
+	if err != nil {
+		return util.Detached{}, err
+	}
+
+	mkfifo := command.NewBuilder("mkfifo", "-m", "666", clusterUploadFIFO, command.Raw("2>/dev/null;"))
+	writeCommit := func(commit bool) *command.Builder {
+		b, _ := json.Marshal(clusterUploadCommit{Commit: commit})
+		return command.NewBuilder(
+			"echo", string(b), command.Raw(">"), clusterUploadCommitFile+".tmp", command.Raw("&&"),
+			"mv", clusterUploadCommitFile+".tmp", clusterUploadCommitFile,
+		)
+	}
+
+	job := command.NewBuilder(
+		command.Raw(mkfifo.String()),
+		command.Raw("if"), "sh", "-c", command.Raw(`"$`+dumpCommandEnv+`"`), command.Raw(">"), clusterUploadFIFO,
+		command.Raw("; then"), command.Raw(writeCommit(true).String()),
+		command.Raw("; else"), command.Raw(writeCommit(false).String()), command.Raw("; exit 1; fi"),
+	)
+	uploader := command.NewBuilder(
+		command.Raw(mkfifo.String()),
+		"exec", "kubedb", "cluster-upload", "--detached",
+	)
+
+	return util.Detached{
+		Env: map[string]string{
+			dumpCommandEnv:          dumpCmd.String(),
+			clusterUploadRequestEnv: string(reqJSON),
+		},
+		Commands: map[string][]string{
+			util.JobContainer:      {"sh", "-c", job.String()},
+			util.UploaderContainer: {"sh", "-c", uploader.String()},
+		},
+		Annotations: map[string]string{
+			util.OutputAnnotation: strings.Join(action.Output, ","),
+		},
+	}, nil
+}
+
+// ClusterUploadDetached runs in the uploader container of a detached job.
+// The request is loaded from the environment, and the commit is read from a file
+// which the job container writes when the database client exits.
+func ClusterUploadDetached(ctx context.Context, w io.Writer) error {
+	r := io.MultiReader(
+		strings.NewReader(os.Getenv(clusterUploadRequestEnv)),
+		&commitFile{ctx: ctx, path: clusterUploadCommitFile},
+	)
+
+	err := ClusterUpload(ctx, r, w)
+	if err != nil {
+		// Unblock the database client if the FIFO was never opened
+		if f, openErr := os.OpenFile(clusterUploadFIFO, os.O_RDONLY|syscall.O_NONBLOCK, 0); openErr == nil {
+			_ = f.Close()
+		}
+	}
+	return err
+}
+
+// commitFile reads a file once it exists.
+type commitFile struct {
+	ctx  context.Context //nolint:containedctx
+	path string
+	r    io.Reader
+}
+
+func (c *commitFile) Read(p []byte) (int, error) {
+	for c.r == nil {
+		b, err := os.ReadFile(c.path)
+		switch {
+		case err == nil:
+			c.r = bytes.NewReader(b)
+		case !errors.Is(err, fs.ErrNotExist):
+			return 0, err
+		default:
+			select {
+			case <-c.ctx.Done():
+				return 0, c.ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	}
+	return c.r.Read(p)
+}
+
+// ParseClusterUploadEvent parses a line of uploader output.
+// Lines which are not events, such as logs, return false.
+func ParseClusterUploadEvent(line []byte) (*ClusterUploadEvent, bool) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	var event ClusterUploadEvent
+	if err := dec.Decode(&event); err != nil {
+		return nil, false
+	}
+	if event.Written == 0 && event.Result == nil {
+		return nil, false
+	}
+	return &event, true
+}
@@ -0,0 +1,90 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/postgres"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDump_Detached(t *testing.T) {
+	action := Dump{Dump: conftypes.Dump{
+		Global: &conftypes.Global{
+			Dialect:  postgres.Postgres{},
+			DBPod:    corev1.Pod{Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+			Database: "d",
+			Username: "u",
+			Password: "p",
+		},
+		Output: []string{"s3://bucket/a.sql.gz", "pvc://backups/a.sql.gz"},
+		Format: sqlformat.Gzip,
+	}}
+
+	got, err := action.Detached()
+	require.NoError(t, err)
+	assert.Empty(t, action.Host)
+
+	assert.Contains(t, got.Env[dumpCommandEnv], "--host=10.0.0.1")
+	var req clusterUploadRequest
+	require.NoError(t, json.Unmarshal([]byte(got.Env[clusterUploadRequestEnv]), &req))
+	assert.Equal(t, []string{"s3://bucket/a.sql.gz", "/kubedb-pvc/backups/a.sql.gz"}, req.Output)
+
+	assert.Contains(t, got.Commands[util.JobContainer][2], `sh -c "$`+dumpCommandEnv+`" > `+clusterUploadFIFO)
+	assert.Contains(t, got.Commands[util.UploaderContainer][2], "exec kubedb cluster-upload --detached")
+	assert.Equal(t, "s3://bucket/a.sql.gz,pvc://backups/a.sql.gz", got.Annotations[util.OutputAnnotation])
+}
+
+func Test_commitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commit")
+
+	t.Run("exists", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"commit":true}`), 0o600))
+		t.Cleanup(func() { _ = os.Remove(path) })
+
+		got, err := io.ReadAll(&commitFile{ctx: t.Context(), path: path})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"commit":true}`, string(got))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := io.ReadAll(&commitFile{ctx: ctx, path: path})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestParseClusterUploadEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   *ClusterUploadEvent
+		wantOk bool
+	}{
+		{"written", `{"written":1024}`, &ClusterUploadEvent{Written: 1024}, true},
+		{"result", `{"result":{"size":10,"outputs":[{"path":"s3://bucket/a.sql"}]}}`, &ClusterUploadEvent{
+			Result: &ClusterUploadResult{Size: 10, Outputs: []ClusterUploadOutput{{Path: "s3://bucket/a.sql"}}},
+		}, true},
+		{"log", `time=2024-01-02 level=INFO msg="Uploading"`, nil, false},
+		{"json log", `{"level":"INFO","msg":"Uploading"}`, nil, false},
+		{"empty object", `{}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseClusterUploadEvent([]byte(tt.line))
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,32 +1,38 @@
 package dump
 
 import (
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"filippo.io/age"
 	"gabe565.com/utils/bytefmt"
 	"gabe565.com/utils/slogx"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/clevyr/kubedb/internal/command"
 	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
 	"github.com/clevyr/kubedb/internal/finalizer"
 	"github.com/clevyr/kubedb/internal/github"
 	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/manifest"
 	"github.com/clevyr/kubedb/internal/notifier"
 	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/ratelimit"
 	"github.com/clevyr/kubedb/internal/storage"
 	"github.com/clevyr/kubedb/internal/tui"
 	"github.com/clevyr/kubedb/internal/util"
 	"github.com/muesli/termenv"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
 type Dump struct {
@@ -35,59 +41,57 @@ type Dump struct {
 
 //nolint:funlen
 func (action Dump) Run(ctx context.Context) error {
-	errGroup, ctx := errgroup.WithContext(ctx)
-
-	var f io.WriteCloser
-	var rename bool
-	switch {
-	case action.Output == "-":
-		f = os.Stdout
-	case storage.IsCloud(action.Output):
-		client, err := storage.NewClient(ctx, action.Output)
-		if err != nil {
+	var recipients []age.Recipient
+	if encryption.Enabled(action.Recipients, action.Passphrase) {
+		var err error
+		if recipients, err = encryption.ParseRecipients(action.Recipients, action.Passphrase); err != nil {
 			return err
 		}
+	}
 
-		pr, pw := io.Pipe()
-		f = pw
-		defer func(pw *io.PipeWriter) {
-			_ = pw.Close()
-		}(pw)
+	limit, err := ratelimit.Parse(action.LimitRate)
+	if err != nil {
+		return err
+	}
+	limiter := ratelimit.NewLimiter(limit)
 
-		errGroup.Go(func() error {
-			defer func() {
-				_ = pr.Close()
-			}()
-			return client.PutObject(ctx, pr, action.Output)
-		})
-	default:
-		dir := filepath.Dir(action.Output)
-		if err := os.MkdirAll(dir, 0o755); err != nil && !os.IsExist(err) {
-			return err
-		}
+	splitSize, err := util.ParseSize(action.SplitSize)
+	if err != nil {
+		return err
+	}
+
+	putOpts := action.putOptions()
+	if err := putOpts.Validate(); err != nil {
+		return err
+	}
 
-		tmp, err := os.CreateTemp(dir, filepath.Base(action.Output)+"-*")
+	if action.ClusterUpload.Enabled {
+		return action.runInCluster(ctx, splitSize, putOpts)
+	}
+
+	outs := make(outputs, 0, len(action.Output))
+	defer func() {
+		for _, o := range outs {
+			o.cleanup(nil)
+		}
+	}()
+	for _, path := range action.Output {
+		o, err := openOutput(ctx, path, splitSize, putOpts)
 		if err != nil {
 			return err
 		}
-		defer func() {
-			_ = tmp.Close()
-			_ = os.Remove(tmp.Name())
-		}()
-
-		f = tmp
-		rename = true
+		outs = append(outs, o)
 	}
 
 	actionLog := slog.With(
 		"namespace", action.Client.Namespace,
 		"pod", action.DBPod.Name,
-		"file", action.Output,
+		"file", strings.Join(action.Output, ", "),
 	)
 
 	actionLog.Info("Exporting database")
 
-	if err := github.SetOutput("filename", action.Output); err != nil {
+	if err := github.SetOutput("filename", action.Output[0]); err != nil {
 		return err
 	}
 
@@ -95,12 +99,17 @@ func (action Dump) Run(ctx context.Context) error {
 	bar := progressbar.New(os.Stderr, -1, "downloading", action.Progress, action.Spinner)
 	defer bar.Close()
 
-	pr, pw := io.Pipe()
-	errGroup.Go(func() error {
-		// Begin database export
-		defer func(pw io.WriteCloser) {
-			_ = pw.Close()
-		}(pw)
+	var written atomic.Int64
+	finalizer.Add(func(err error) {
+		action.printSummary(err, outs, time.Since(startTime).Truncate(10*time.Millisecond), written.Load())
+	})
+
+	digest := manifest.NewDigest()
+	st := stream{Format: action.Format, Compressed: action.remoteCompression(), Recipients: recipients}
+	err = export(ctx, outs, st, limiter, &written, func(ctx context.Context, w io.Writer) error {
+		if action.Staged {
+			return action.runStaged(ctx, w, bar.Logger())
+		}
 
 		cmd, err := action.buildCommand()
 		if err != nil {
@@ -111,87 +120,129 @@ func (action Dump) Run(ctx context.Context) error {
 			Pod:         action.JobPod,
 			Cmd:         cmd.String(),
 			Stdin:       os.Stdin,
-			Stdout:      pw,
+			Stdout:      w,
 			Stderr:      bar.Logger(),
 			DisablePing: true,
 		})
-	})
-
-	if !action.RemoteGzip && action.Format == sqlformat.Gzip {
-		// Gzip locally
-		gzPipeReader, gzPipeWriter := io.Pipe()
-		plainReader := pr
-		errGroup.Go(func() error {
-			defer func() {
-				_ = gzPipeWriter.Close()
-				_ = plainReader.Close()
-			}()
-
-			gzw := gzip.NewWriter(gzPipeWriter)
-			if _, err := io.Copy(gzw, plainReader); err != nil {
-				return err
-			}
-			return gzw.Close()
-		})
-		pr = gzPipeReader
+	}, bar, digest)
+	if err != nil {
+		return err
 	}
 
-	var written atomic.Int64
+	_ = bar.Finish()
+
+	m := action.manifest()
+	m.SHA256 = digest.Sum()
+	m.Size = digest.Size()
+	outs.writeManifests(ctx, m, putOpts)
+
+	return action.complete(ctx, outs, time.Since(startTime), written.Load())
+}
+
+// export streams the dump written by run into outs. The outputs are only
+// moved into place once both the export and the copy have succeeded.
+func export(
+	ctx context.Context,
+	outs outputs,
+	st stream,
+	limiter *rate.Limiter,
+	written *atomic.Int64,
+	run func(ctx context.Context, w io.Writer) error,
+	w ...io.Writer,
+) error {
+	errGroup, ctx := errgroup.WithContext(ctx)
+
+	pr, pw := io.Pipe()
+	errGroup.Go(func() (err error) {
+		defer func(pw *io.PipeWriter) {
+			// Pass errors through so the copy does not see a clean EOF
+			_ = pw.CloseWithError(err)
+		}(pw)
+		return run(ctx, pw)
+	})
+
 	errGroup.Go(func() error {
-		// Begin copying export to local file
 		defer func(pr io.ReadCloser) {
 			_ = pr.Close()
 		}(pr)
 
-		r := io.Reader(pr)
-		if action.RemoteGzip {
-			if action.Format == sqlformat.Plain {
-				var err error
-				if r, err = gzip.NewReader(r); err != nil {
-					return err
-				}
-			}
-		}
-
-		n, err := io.Copy(io.MultiWriter(f, bar), r) //nolint:gosec
+		n, err := st.write(ratelimit.NewReader(ctx, pr, limiter), outs, w...)
 		written.Add(n)
-		if err != nil {
-			return err
-		}
-		return f.Close()
-	})
-
-	finalizer.Add(func(err error) {
-		action.printSummary(err, time.Since(startTime).Truncate(10*time.Millisecond), written.Load())
+		return err
 	})
 
 	if err := errGroup.Wait(); err != nil {
 		return err
 	}
+	return outs.finish()
+}
 
-	_ = bar.Finish()
+// complete logs the result and passes the summary to the notifier.
+func (action Dump) complete(ctx context.Context, outs outputs, took time.Duration, written int64) error {
+	actionLog := slog.With(
+		"namespace", action.Client.Namespace,
+		"pod", action.DBPod.Name,
+		"file", strings.Join(action.Output, ", "),
+	)
 
-	if rename {
-		if f, ok := f.(*os.File); ok {
-			if err := os.Rename(f.Name(), action.Output); err != nil {
-				return err
-			}
-		}
+	took = took.Truncate(10 * time.Millisecond)
+	err := outs.Err()
+	if err == nil {
+		actionLog.Info("Dump complete", "took", took, "size", bytefmt.Encode(written))
+	} else {
+		// Some outputs failed, but the others were written successfully
+		actionLog.Warn("Dump complete with errors", "took", took, "size", bytefmt.Encode(written))
 	}
 
-	actionLog.Info("Dump complete",
-		"took", time.Since(startTime).Truncate(10*time.Millisecond),
-		"size", bytefmt.Encode(written.Load()),
-	)
-
 	if handler, ok := notifier.FromContext(ctx); ok {
 		if logger, ok := handler.(notifier.Logs); ok {
-			logger.SetLog(
-				action.summary(nil, time.Since(startTime).Truncate(10*time.Millisecond), written.Load(), true),
-			)
+			logger.SetLog(action.summary(err, outs, took, written, true))
 		}
 	}
-	return nil
+	return err
+}
+
+// manifest returns the manifest fields which describe the database.
+func (action Dump) manifest() manifest.Manifest {
+	return manifest.Manifest{
+		Dialect:   action.Dialect.Name(),
+		Format:    action.Format,
+		Namespace: action.Namespace,
+		Pod:       action.DBPod.Name,
+		Database:  action.Database,
+	}
+}
+
+func (action Dump) remoteCompression() bool {
+	return action.Format != sqlformat.Custom && (action.RemoteGzip || action.RemoteZstd)
+}
+
+// Ext returns the extension added to generated filenames after the format extension.
+func (action Dump) Ext() string {
+	if encryption.Enabled(action.Recipients, action.Passphrase) {
+		return encryption.Ext
+	}
+	return ""
+}
+
+// putOptions returns the upload options. Metadata describing the dump is added to the configured metadata.
+func (action Dump) putOptions() storage.PutOptions {
+	metadata := map[string]string{
+		"kubedb_namespace": action.Namespace,
+		"kubedb_dialect":   action.Dialect.Name(),
+	}
+	if version := util.GetVersion(); version != "" {
+		metadata["kubedb_version"] = version
+	}
+	maps.Copy(metadata, action.Metadata)
+
+	return storage.PutOptions{
+		SSE:          action.SSE,
+		KMSKeyID:     action.SSEKMSKeyID,
+		StorageClass: action.StorageClass,
+		Tags:         action.Tags,
+		Metadata:     metadata,
+	}
 }
 
 func (action Dump) buildCommand() (*command.Builder, error) {
@@ -207,14 +258,19 @@ func (action Dump) buildCommand() (*command.Builder, error) {
 	cmd.Unshift(command.Raw("{"))
 	cmd.Push(command.Raw("|| kill $$; }"))
 
-	if action.RemoteGzip && action.Format != sqlformat.Custom {
-		cmd.Push(command.Pipe, "gzip", "--force")
+	if action.Format != sqlformat.Custom {
+		switch {
+		case action.RemoteZstd:
+			cmd.Push(command.Pipe, command.Raw("{ if command -v zstd >/dev/null; then zstd --stdout -T0; else gzip --force; fi; }"))
+		case action.RemoteGzip:
+			cmd.Push(command.Pipe, "gzip", "--force")
+		}
 	}
 	slogx.Trace("Finished building command", "cmd", cmd)
 	return cmd, nil
 }
 
-func (action Dump) summary(err error, took time.Duration, written int64, plain bool) string {
+func (action Dump) summary(err error, outs outputs, took time.Duration, written int64, plain bool) string {
 	var r *lipgloss.Renderer
 	if plain {
 		r = lipgloss.NewRenderer(os.Stdout, termenv.WithTTY(false))
@@ -222,13 +278,21 @@ func (action Dump) summary(err error, took time.Duration, written int64, plain b
 		r.SetHasDarkBackground(lipgloss.HasDarkBackground())
 	}
 
+	var uploader string
+	if action.ClusterUpload.Enabled {
+		uploader = action.JobPod.Name
+	}
+
 	t := tui.MinimalTable(r).
 		RowIfNotEmpty("Context", action.Context).
 		Row("Namespace", tui.NamespaceStyle(r, action.Global.NamespaceColors, action.Namespace).Render()).
 		Row("Pod", action.DBPod.Name).
 		RowIfNotEmpty("Username", action.Username).
 		RowIfNotEmpty("Database", action.Database).
-		Row("File", tui.OutPath(action.Output, r)).
+		Row(outs.label(), outs.summary(r)).
+		RowIfNotEmpty("Uploaded From", uploader).
+		RowIfNotEmpty("Recipients", strings.Join(encryption.Describe(action.Recipients, action.Passphrase), "\n")).
+		RowIfNotEmpty("Rate Limit", action.LimitRate).
 		Row("Took", took.String())
 	if err != nil {
 		t.Row("Error", tui.ErrStyle(r).Render(err.Error()))
@@ -246,10 +310,10 @@ func (action Dump) summary(err error, took time.Duration, written int64, plain b
 	)
 }
 
-func (action Dump) printSummary(err error, took time.Duration, written int64) {
+func (action Dump) printSummary(err error, outs outputs, took time.Duration, written int64) {
 	out := os.Stdout
-	if action.Output == "-" {
+	if slices.Contains(action.Output, "-") {
 		out = os.Stderr
 	}
-	_, _ = io.WriteString(out, "\n"+action.summary(err, took, written, false)+"\n")
+	_, _ = io.WriteString(out, "\n"+action.summary(err, outs, took, written, false)+"\n")
 }
@@ -1,6 +1,15 @@
 package dump
 
 import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/clevyr/kubedb/internal/command"
@@ -8,6 +17,10 @@ import (
 	"github.com/clevyr/kubedb/internal/database/mariadb"
 	"github.com/clevyr/kubedb/internal/database/postgres"
 	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/ratelimit"
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +64,36 @@ func Test_buildCommand(t *testing.T) {
 			),
 			require.NoError,
 		},
+		{
+			"postgres-zstd",
+			args{
+				Dump{
+					Dump: conftypes.Dump{
+						Format: sqlformat.Zstd,
+						Global: &conftypes.Global{
+							Dialect:    postgres.Postgres{},
+							Host:       "1.1.1.1",
+							Database:   "d",
+							Username:   "u",
+							RemoteGzip: true,
+							RemoteZstd: true,
+						},
+					},
+				},
+			},
+			command.NewBuilder(
+				command.Raw("{"),
+				"pg_dump",
+				"--host=1.1.1.1",
+				"--username=u",
+				"--dbname=d",
+				"--verbose",
+				command.Raw("|| kill $$; }"),
+				command.Pipe,
+				command.Raw("{ if command -v zstd >/dev/null; then zstd --stdout -T0; else gzip --force; fi; }"),
+			),
+			require.NoError,
+		},
 		{
 			"postgres-gzip-no-compression",
 			args{
@@ -173,3 +216,184 @@ func Test_buildCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestDump_putOptions(t *testing.T) {
+	action := Dump{Dump: conftypes.Dump{
+		Global: &conftypes.Global{Dialect: postgres.Postgres{}, Kubernetes: conftypes.Kubernetes{Namespace: "prod"}},
+		Upload: conftypes.Upload{
+			SSEKMSKeyID:  "key",
+			StorageClass: "GLACIER_IR",
+			Tags:         map[string]string{"team": "db"},
+			Metadata:     map[string]string{"kubedb_dialect": "custom", "owner": "ops"},
+		},
+	}}
+
+	got := action.putOptions()
+	assert.Equal(t, "key", got.KMSKeyID)
+	assert.Equal(t, "GLACIER_IR", got.StorageClass)
+	assert.Equal(t, map[string]string{"team": "db"}, got.Tags)
+	assert.Equal(t, "prod", got.Metadata["kubedb_namespace"])
+	assert.Equal(t, "custom", got.Metadata["kubedb_dialect"])
+	assert.Equal(t, "ops", got.Metadata["owner"])
+}
+
+// memClient stores objects only if the upload completes, like a multipart upload.
+type memClient struct {
+	storage.Client
+	mu      sync.Mutex
+	objects map[string]string
+	// errPut is returned for keys with this suffix
+	failSuffix string
+}
+
+var errPut = errors.New("put failed")
+
+func (m *memClient) PutObject(_ context.Context, r io.Reader, key string, _ storage.PutOptions) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m.failSuffix != "" && strings.HasSuffix(key, m.failSuffix) {
+		return errPut
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = string(b)
+	return nil
+}
+
+func (m *memClient) DeleteObject(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memClient) ListObjects(context.Context, string) iter.Seq2[*storage.Object, error] {
+	return nil
+}
+
+func Test_export(t *testing.T) {
+	errExec := errors.New("exec failed")
+
+	tests := []struct {
+		name    string
+		run     func(context.Context, io.Writer) error
+		wantErr error
+	}{
+		{"success", func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "SELECT 1;")
+			return err
+		}, nil},
+		{"exec error", func(_ context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "SELECT")
+			return errExec
+		}, errExec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file, err := openOutput(t.Context(), filepath.Join(dir, "a.sql"), 0, storage.PutOptions{})
+			require.NoError(t, err)
+			client := &memClient{objects: make(map[string]string)}
+			object := &output{Path: "s3://bucket/a.sql"}
+			object.startUpload(t.Context(), client, storage.PutOptions{})
+			outs := outputs{file, object}
+
+			var written atomic.Int64
+			err = export(t.Context(), outs, stream{Format: sqlformat.Plain}, ratelimit.NewLimiter(0), &written, tt.run)
+			for _, o := range outs {
+				o.cleanup(err)
+			}
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				// Wait for the canceled upload
+				<-object.upload
+				entries, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+				assert.Empty(t, client.objects)
+				return
+			}
+
+			require.NoError(t, err)
+			got, err := os.ReadFile(file.Path)
+			require.NoError(t, err)
+			assert.Equal(t, "SELECT 1;", string(got))
+			assert.Equal(t, map[string]string{"s3://bucket/a.sql": "SELECT 1;"}, client.objects)
+		})
+	}
+}
+
+func Test_export_split(t *testing.T) {
+	dir := t.TempDir()
+	o, err := openOutput(t.Context(), filepath.Join(dir, "a.sql"), 4, storage.PutOptions{})
+	require.NoError(t, err)
+	outs := outputs{o}
+
+	errExec := errors.New("exec failed")
+	var written atomic.Int64
+	err = export(t.Context(), outs, stream{Format: sqlformat.Plain}, ratelimit.NewLimiter(0), &written,
+		func(_ context.Context, w io.Writer) error {
+			// Finishes the first volumes before failing
+			_, _ = io.WriteString(w, "SELECT 1;")
+			return errExec
+		},
+	)
+	require.ErrorIs(t, err, errExec)
+	o.cleanup(err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_export_splitManifestFailed(t *testing.T) {
+	run := func(_ context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "SELECT 1;")
+		return err
+	}
+
+	t.Run("local", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "a.sql")
+		// A directory in place of the manifest makes it fail to write
+		require.NoError(t, os.Mkdir(manifest.Path(path), 0o755))
+
+		o, err := openOutput(t.Context(), path, 4, storage.PutOptions{})
+		require.NoError(t, err)
+		outs := outputs{o}
+
+		var written atomic.Int64
+		require.NoError(t, export(t.Context(), outs, stream{Format: sqlformat.Plain}, ratelimit.NewLimiter(0), &written, run))
+		outs.writeManifests(t.Context(), manifest.Manifest{}, storage.PutOptions{})
+		require.Error(t, o.Err)
+		o.cleanup(nil)
+
+		for n := 1; n <= 3; n++ {
+			assert.FileExists(t, split.Path(path, n))
+		}
+	})
+
+	t.Run("object", func(t *testing.T) {
+		client := &memClient{objects: make(map[string]string), failSuffix: manifest.Ext}
+		o := &output{Path: "s3://bucket/a.sql", Client: client}
+		o.split = split.NewWriter(4, func(n int) (io.WriteCloser, error) {
+			vol := &output{Path: split.Path(o.Path, n)}
+			vol.startUpload(t.Context(), client, storage.PutOptions{})
+			o.volumes = append(o.volumes, vol)
+			return volume{vol}, nil
+		})
+		o.w = o.split
+		outs := outputs{o}
+
+		var written atomic.Int64
+		require.NoError(t, export(t.Context(), outs, stream{Format: sqlformat.Plain}, ratelimit.NewLimiter(0), &written, run))
+		outs.writeManifests(t.Context(), manifest.Manifest{}, storage.PutOptions{})
+		require.ErrorIs(t, o.Err, errPut)
+		o.cleanup(nil)
+
+		assert.Len(t, client.objects, 3)
+	})
+}
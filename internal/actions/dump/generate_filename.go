@@ -1,26 +1,330 @@
 package dump
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
+	"unicode"
+
+	"github.com/clevyr/kubedb/internal/consts"
 )
 
 const DateFormat = "2006-01-02_150405"
 
 type Filename struct {
-	Database  string
+	Context   string
 	Namespace string
+	Pod       string
+	Dialect   string
+	Database  string
+	Username  string
 	Ext       string
 	Date      time.Time
 }
 
-func (vars Filename) Generate() string {
-	result := vars.Namespace + "_"
-	switch vars.Database {
-	case "":
-	case vars.Namespace:
-	default:
-		result += vars.Database + "_"
+// Timestamp is the type of the Date template field.
+type Timestamp interface {
+	Format(layout string) string
+	UTC() Timestamp
+	Local() Timestamp
+}
+
+type timestamp time.Time
+
+func (t timestamp) Format(layout string) string { return time.Time(t).Format(layout) }
+func (t timestamp) UTC() Timestamp              { return timestamp(time.Time(t).UTC()) }
+func (t timestamp) Local() Timestamp            { return timestamp(time.Time(t).Local()) }
+
+type templateData struct {
+	Context   string
+	Namespace string
+	Pod       string
+	Dialect   string
+	Database  string
+	Username  string
+	Ext       string
+	Date      Timestamp
+}
+
+var pathReplacer = strings.NewReplacer("/", "-", `\`, "-") //nolint:gochecknoglobals
+
+var (
+	ErrInvalidFilename = errors.New("filename was not generated by kubedb")
+	ErrInvalidTemplate = errors.New("invalid filename template")
+)
+
+// FilenameTemplate generates dump filenames and parses them back into their fields.
+type FilenameTemplate struct {
+	tmpl     *template.Template
+	patterns []filenamePattern
+	depth    int
+}
+
+// NewFilenameTemplate parses a text/template filename. If text is empty, consts.DefaultFilenameTemplate is used.
+func NewFilenameTemplate(text string) (*FilenameTemplate, error) {
+	if text == "" {
+		text = consts.DefaultFilenameTemplate
+	}
+
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	t := &FilenameTemplate{tmpl: tmpl}
+	if err := t.compile(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Generate renders the filename. Slashes in the template create subdirectories,
+// but slashes in field values are replaced so that names can be parsed.
+func (t *FilenameTemplate) Generate(vars Filename) (string, error) {
+	var buf strings.Builder
+	if err := t.tmpl.Execute(&buf, templateData{
+		Context:   pathReplacer.Replace(vars.Context),
+		Namespace: pathReplacer.Replace(vars.Namespace),
+		Pod:       pathReplacer.Replace(vars.Pod),
+		Dialect:   pathReplacer.Replace(vars.Dialect),
+		Database:  pathReplacer.Replace(vars.Database),
+		Username:  pathReplacer.Replace(vars.Username),
+		Ext:       vars.Ext,
+		Date:      timestamp(vars.Date),
+	}); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+// Depth returns the maximum number of subdirectories the template can generate.
+func (t *FilenameTemplate) Depth() int {
+	return t.depth
+}
+
+// Parse is the inverse of Generate. Leading directories which are not part of the template are ignored.
+func (t *FilenameTemplate) Parse(name string) (Filename, error) {
+	name = strings.TrimPrefix(path.Clean(strings.ReplaceAll(name, `\`, "/")), "/")
+	parts := strings.Split(name, "/")
+
+	var err error
+	for _, p := range t.patterns {
+		if len(parts) <= p.depth {
+			continue
+		}
+
+		var filename Filename
+		filename, err = p.parse(strings.Join(parts[len(parts)-p.depth-1:], "/"))
+		if err == nil {
+			return filename, nil
+		}
+	}
+	if err == nil || errors.Is(err, ErrInvalidFilename) {
+		return Filename{}, fmt.Errorf("%w: %s", ErrInvalidFilename, name)
+	}
+	return Filename{}, fmt.Errorf("%w: %s: %w", ErrInvalidFilename, name, err)
+}
+
+const sentinel = "\x00"
+
+// placeholderTimestamp renders a placeholder which records the requested layout and location.
+type placeholderTimestamp struct {
+	utc bool
+}
+
+func (t placeholderTimestamp) Format(layout string) string {
+	loc := "Local"
+	if t.utc {
+		loc = "UTC"
+	}
+	return sentinel + "Date:" + loc + ":" + layout + sentinel
+}
+func (t placeholderTimestamp) UTC() Timestamp   { return placeholderTimestamp{utc: true} }
+func (t placeholderTimestamp) Local() Timestamp { return placeholderTimestamp{} }
+
+type filenamePattern struct {
+	re     *regexp.Regexp
+	fields []string
+	layout string
+	loc    *time.Location
+	depth  int
+	count  int
+}
+
+// optionalFields may be empty when a filename is generated, which can change the template output.
+var optionalFields = []string{"Context", "Pod", "Dialect", "Database", "Username"} //nolint:gochecknoglobals
+
+// compile renders the template once for each combination of optional fields using placeholder values,
+// then converts each unique output into a regex.
+func (t *FilenameTemplate) compile() error {
+	seen := make(map[string]struct{})
+	for mask := range 1 << len(optionalFields) {
+		data := templateData{
+			Namespace: sentinel + "Namespace" + sentinel,
+			Ext:       sentinel + "Ext" + sentinel,
+			Date:      placeholderTimestamp{},
+		}
+		var count int
+		for i, field := range optionalFields {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			count++
+			value := sentinel + field + sentinel
+			switch field {
+			case "Context":
+				data.Context = value
+			case "Pod":
+				data.Pod = value
+			case "Dialect":
+				data.Dialect = value
+			case "Database":
+				data.Database = value
+			case "Username":
+				data.Username = value
+			}
+		}
+
+		var buf strings.Builder
+		if err := t.tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+		if _, ok := seen[buf.String()]; ok {
+			continue
+		}
+		seen[buf.String()] = struct{}{}
+
+		p, err := newFilenamePattern(buf.String())
+		if err != nil {
+			return err
+		}
+		p.count = count
+		t.patterns = append(t.patterns, p)
+		t.depth = max(t.depth, p.depth)
+	}
+
+	// Prefer patterns which capture the most fields
+	slices.SortStableFunc(t.patterns, func(a, b filenamePattern) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	return nil
+}
+
+func newFilenamePattern(rendered string) (filenamePattern, error) {
+	p := filenamePattern{
+		loc:   time.Local,
+		depth: strings.Count(strings.ReplaceAll(rendered, sentinel, ""), "/"),
+	}
+
+	var re strings.Builder
+	re.WriteString("^")
+	var layouts []string
+	for i, part := range strings.Split(rendered, sentinel) {
+		if i%2 == 0 {
+			re.WriteString(regexp.QuoteMeta(part))
+			continue
+		}
+
+		if layout, ok := strings.CutPrefix(part, "Date:"); ok {
+			loc, layout, _ := strings.Cut(layout, ":")
+			if loc == "UTC" {
+				p.loc = time.UTC
+			}
+			layouts = append(layouts, layout)
+			re.WriteString("(" + layoutPattern(layout) + ")")
+			p.fields = append(p.fields, "Date")
+			continue
+		}
+
+		if part == "Ext" {
+			re.WriteString(`(\.[^/]+|)`)
+		} else {
+			re.WriteString(`([^/]+?)`)
+		}
+		p.fields = append(p.fields, part)
+	}
+	re.WriteString("$")
+
+	if len(layouts) == 0 {
+		return p, fmt.Errorf("%w: the date is required to parse filenames", ErrInvalidTemplate)
+	}
+	if !slices.Contains(p.fields, "Namespace") {
+		return p, fmt.Errorf("%w: the namespace is required to parse filenames", ErrInvalidTemplate)
+	}
+
+	var err error
+	if p.re, err = regexp.Compile(re.String()); err != nil {
+		return p, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	p.layout = strings.Join(layouts, sentinel)
+	return p, nil
+}
+
+// layoutPattern converts a time layout into a regex which matches its output.
+func layoutPattern(layout string) string {
+	var re strings.Builder
+	for i := 0; i < len(layout); {
+		r := rune(layout[i])
+		j := i + 1
+		switch {
+		case unicode.IsDigit(r):
+			for j < len(layout) && unicode.IsDigit(rune(layout[j])) {
+				j++
+			}
+			re.WriteString(`\d+`)
+		case unicode.IsLetter(r):
+			for j < len(layout) && unicode.IsLetter(rune(layout[j])) {
+				j++
+			}
+			re.WriteString(`[A-Za-z]+`)
+		default:
+			re.WriteString(regexp.QuoteMeta(layout[i:j]))
+		}
+		i = j
+	}
+	return re.String()
+}
+
+func (p filenamePattern) parse(name string) (Filename, error) {
+	matches := p.re.FindStringSubmatch(name)
+	if matches == nil {
+		return Filename{}, ErrInvalidFilename
+	}
+
+	var filename Filename
+	values := make([]string, 0, 1)
+	for i, field := range p.fields {
+		value := matches[i+1]
+		switch field {
+		case "Context":
+			filename.Context = value
+		case "Namespace":
+			filename.Namespace = value
+		case "Pod":
+			filename.Pod = value
+		case "Dialect":
+			filename.Dialect = value
+		case "Database":
+			filename.Database = value
+		case "Username":
+			filename.Username = value
+		case "Ext":
+			filename.Ext = value
+		case "Date":
+			values = append(values, value)
+		}
+	}
+
+	date, err := time.ParseInLocation(p.layout, strings.Join(values, sentinel), p.loc)
+	if err != nil {
+		return Filename{}, err
 	}
-	result += vars.Date.Format(DateFormat) + vars.Ext
-	return result
+	filename.Date = date.In(time.Local)
+	return filename, nil
 }
@@ -5,45 +5,171 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestFilename_Generate(t *testing.T) {
-	type fields struct {
-		Database  string
-		Namespace string
-		Ext       string
-		Date      time.Time
-	}
+func TestFilenameTemplate_Generate(t *testing.T) {
+	date := time.Date(2024, 3, 5, 13, 4, 5, 0, time.Local)
 	tests := []struct {
 		name    string
-		fields  fields
+		tmpl    string
+		vars    Filename
 		want    string
-		wantErr bool
+		wantErr require.ErrorAssertionFunc
 	}{
-		{"no database", fields{"", "test", ".sql.gz", time.Time{}}, "test_0001-01-01_000000.sql.gz", false},
+		{"no database", "", Filename{Namespace: "test", Ext: ".sql.gz"}, "test_0001-01-01_000000.sql.gz", require.NoError},
 		{
 			"with database",
-			fields{"postgres", "test", ".sql.gz", time.Time{}},
+			"",
+			Filename{Database: "postgres", Namespace: "test", Ext: ".sql.gz"},
 			"test_postgres_0001-01-01_000000.sql.gz",
-			false,
+			require.NoError,
 		},
 		{
 			"database matches namespace",
-			fields{"test", "test", ".sql.gz", time.Time{}},
+			"",
+			Filename{Database: "test", Namespace: "test", Ext: ".sql.gz"},
 			"test_0001-01-01_000000.sql.gz",
-			false,
+			require.NoError,
+		},
+		{
+			"folders",
+			`{{ .Date.Format "2006/01" }}/{{ .Context }}_{{ .Namespace }}_{{ .Dialect }}_{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`,
+			Filename{Context: "prod", Namespace: "test", Dialect: "Postgres", Ext: ".sql.gz", Date: date},
+			"2024/03/prod_test_Postgres_2024-03-05_130405.sql.gz",
+			require.NoError,
+		},
+		{
+			"slash in value",
+			"",
+			Filename{Namespace: "test", Database: "a/b", Ext: ".sql.gz", Date: date},
+			"test_a-b_2024-03-05_130405.sql.gz",
+			require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewFilenameTemplate(tt.tmpl)
+			require.NoError(t, err)
+			got, err := tmpl.Generate(tt.vars)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewFilenameTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"default", "", require.NoError},
+		{"utc", `{{ .Namespace }}_{{ .Date.UTC.Format "20060102T150405Z" }}{{ .Ext }}`, require.NoError},
+		{"syntax error", `{{ .Namespace `, require.Error},
+		{"unknown field", `{{ .Namespace }}_{{ .Unknown }}`, require.Error},
+		{"no date", `{{ .Namespace }}{{ .Ext }}`, require.Error},
+		{"no namespace", `{{ .Date.Format "2006-01-02" }}{{ .Ext }}`, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFilenameTemplate(tt.tmpl)
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, ErrInvalidTemplate)
+			}
+		})
+	}
+}
+
+func TestFilenameTemplate_Parse(t *testing.T) {
+	date := time.Date(2024, 3, 5, 13, 4, 5, 0, time.Local)
+	tests := []struct {
+		name    string
+		tmpl    string
+		path    string
+		want    Filename
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"no database", "", "test_2024-03-05_130405.sql.gz", Filename{Namespace: "test", Ext: ".sql.gz", Date: date}, require.NoError},
+		{
+			"with database",
+			"",
+			"test_postgres_2024-03-05_130405.sql.gz",
+			Filename{Namespace: "test", Database: "postgres", Ext: ".sql.gz", Date: date},
+			require.NoError,
+		},
+		{
+			"database with underscore",
+			"",
+			"test_my_db_2024-03-05_130405.dump",
+			Filename{Namespace: "test", Database: "my_db", Ext: ".dump", Date: date},
+			require.NoError,
+		},
+		{
+			"full path",
+			"",
+			"s3://bucket/dir/test_2024-03-05_130405.archive.gz",
+			Filename{Namespace: "test", Ext: ".archive.gz", Date: date},
+			require.NoError,
+		},
+		{
+			"folders",
+			`{{ .Date.Format "2006/01" }}/{{ .Namespace }}/{{ .Dialect }}_{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`,
+			"dir/2024/03/test/Postgres_2024-03-05_130405.sql.gz",
+			Filename{Namespace: "test", Dialect: "Postgres", Ext: ".sql.gz", Date: date},
+			require.NoError,
+		},
+		{
+			"utc",
+			`{{ .Namespace }}_{{ .Date.UTC.Format "20060102T150405Z" }}{{ .Ext }}`,
+			"test_" + date.UTC().Format("20060102T150405Z") + ".sql",
+			Filename{Namespace: "test", Ext: ".sql", Date: date},
+			require.NoError,
+		},
+		{"no date", "", "test.sql.gz", Filename{}, require.Error},
+		{"invalid date", "", "test_2024-13-05_130405.sql.gz", Filename{}, require.Error},
+		{
+			"missing folder",
+			`{{ .Date.Format "2006/01" }}/{{ .Namespace }}_{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`,
+			"test_2024-03-05_130405.sql.gz",
+			Filename{},
+			require.Error,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			vars := Filename{
-				Database:  tt.fields.Database,
-				Namespace: tt.fields.Namespace,
-				Ext:       tt.fields.Ext,
-				Date:      tt.fields.Date,
+			tmpl, err := NewFilenameTemplate(tt.tmpl)
+			require.NoError(t, err)
+			got, err := tmpl.Parse(tt.path)
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, ErrInvalidFilename)
 			}
-			got := vars.Generate()
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestFilenameTemplate_Roundtrip(t *testing.T) {
+	tmpl, err := NewFilenameTemplate(
+		`{{ .Context }}/{{ .Namespace }}/{{ .Pod }}_{{ .Username }}@{{ .Database }}_{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, tmpl.Depth())
+
+	want := Filename{
+		Context:   "prod",
+		Namespace: "test",
+		Pod:       "postgres-0",
+		Database:  "app",
+		Username:  "postgres",
+		Ext:       ".sql.gz",
+		Date:      time.Date(2024, 3, 5, 13, 4, 5, 0, time.Local),
+	}
+	name, err := tmpl.Generate(want)
+	require.NoError(t, err)
+	got, err := tmpl.Parse(name)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,277 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/tui"
+)
+
+var ErrAllOutputsFailed = errors.New("all outputs failed")
+
+// output is a single destination which the dump is written to.
+type output struct {
+	Path   string
+	Client storage.Client
+	Err    error
+
+	w       io.WriteCloser
+	tmp     *os.File
+	upload  chan error
+	split   *split.Writer
+	volumes []*output
+	// committed is set once the data was moved into place. Later errors, like a failed manifest, do not undo it.
+	committed bool
+	// parts is reported by the uploader when the output was written from the cluster
+	parts int
+}
+
+// openOutput prepares path for writing. If splitSize is positive, the dump is written to numbered volumes.
+func openOutput(ctx context.Context, path string, splitSize int64, opts storage.PutOptions) (*output, error) {
+	o := &output{Path: path}
+	switch {
+	case path == "-":
+		o.w = os.Stdout
+	case splitSize > 0:
+		if storage.IsCloud(path) {
+			var err error
+			if o.Client, err = storage.NewClient(ctx, path); err != nil {
+				return nil, err
+			}
+		}
+
+		o.split = split.NewWriter(splitSize, func(n int) (io.WriteCloser, error) {
+			vol, err := openOutput(ctx, split.Path(path, n), 0, opts)
+			if err != nil {
+				return nil, err
+			}
+			o.volumes = append(o.volumes, vol)
+			return volume{vol}, nil
+		})
+		o.w = o.split
+	case storage.IsCloud(path):
+		client, err := storage.NewClient(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		o.startUpload(ctx, client, opts)
+	default:
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0o755); err != nil && !os.IsExist(err) {
+			return nil, err
+		}
+
+		tmp, err := os.CreateTemp(dir, filepath.Base(path)+"-*")
+		if err != nil {
+			return nil, err
+		}
+		o.w = tmp
+		o.tmp = tmp
+	}
+	return o, nil
+}
+
+// startUpload streams writes to an object in client.
+func (o *output) startUpload(ctx context.Context, client storage.Client, opts storage.PutOptions) {
+	pr, pw := io.Pipe()
+	o.Client = client
+	o.w = pw
+	o.upload = make(chan error, 1)
+	go func() {
+		err := client.PutObject(ctx, pr, o.Path, opts)
+		// Unblock writes if the upload stops early
+		_ = pr.CloseWithError(err)
+		o.upload <- err
+	}()
+}
+
+// finish flushes the output and moves it into place.
+func (o *output) finish() error {
+	if o.Err != nil {
+		return o.Err
+	}
+
+	switch {
+	case o.split != nil:
+		o.Err = o.split.Close()
+	case o.upload != nil:
+		if err := o.w.Close(); err != nil {
+			o.Err = err
+			return err
+		}
+		o.Err = <-o.upload
+	case o.tmp != nil:
+		if err := o.tmp.Close(); err != nil {
+			o.Err = err
+			return err
+		}
+		o.Err = os.Rename(o.tmp.Name(), o.Path)
+	}
+	o.committed = o.Err == nil
+	return o.Err
+}
+
+// cleanup aborts an unfinished output. Partial files are removed, and uploads are canceled.
+// Volumes which were already finished are deleted, since the dump is incomplete.
+func (o *output) cleanup(err error) {
+	if o.committed {
+		return
+	}
+	if err == nil {
+		err = context.Canceled
+	}
+
+	switch {
+	case o.split != nil:
+		for _, vol := range o.volumes {
+			if vol.committed {
+				vol.remove()
+			} else {
+				vol.cleanup(err)
+			}
+		}
+	case o.upload != nil:
+		if pw, ok := o.w.(*io.PipeWriter); ok {
+			_ = pw.CloseWithError(err)
+		}
+	case o.tmp != nil:
+		_ = o.tmp.Close()
+		_ = os.Remove(o.tmp.Name())
+	}
+}
+
+// remove deletes a finished output.
+func (o *output) remove() {
+	if o.Client == nil {
+		_ = os.Remove(o.Path)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := o.Client.DeleteObject(ctx, o.Path); err != nil {
+		slog.Warn("Failed to delete incomplete volume", "path", o.Path, "error", err)
+	}
+}
+
+// Parts returns the number of volumes written, or 0 if the output is not split.
+func (o *output) Parts() int {
+	if o.split == nil {
+		return o.parts
+	}
+	return o.split.Parts()
+}
+
+// volume finishes the underlying output when closed.
+type volume struct {
+	*output
+}
+
+func (v volume) Write(p []byte) (int, error) {
+	return v.w.Write(p)
+}
+
+func (v volume) Close() error {
+	return v.finish()
+}
+
+// outputs writes to every output which has not failed yet.
+type outputs []*output
+
+func (outs outputs) Write(p []byte) (int, error) {
+	var ok bool
+	for _, o := range outs {
+		if o.Err != nil {
+			continue
+		}
+		if _, err := o.w.Write(p); err != nil {
+			o.Err = err
+			continue
+		}
+		ok = true
+	}
+	if !ok {
+		return 0, outs.Err()
+	}
+	return len(p), nil
+}
+
+// finish finishes every output. An error is only returned if all outputs failed.
+func (outs outputs) finish() error {
+	var ok bool
+	for _, o := range outs {
+		if err := o.finish(); err == nil {
+			ok = true
+		}
+	}
+	if !ok {
+		return outs.Err()
+	}
+	return nil
+}
+
+// writeManifests writes m next to every output which succeeded.
+func (outs outputs) writeManifests(ctx context.Context, m manifest.Manifest, opts storage.PutOptions) {
+	for _, o := range outs {
+		if o.Err != nil || o.Path == "-" {
+			continue
+		}
+		om := m
+		om.Parts = o.Parts()
+		if err := om.Write(ctx, o.Client, o.Path, opts); err != nil {
+			o.Err = err
+		}
+	}
+}
+
+// Err joins the errors of all failed outputs.
+func (outs outputs) Err() error {
+	if len(outs) == 1 {
+		return outs[0].Err
+	}
+
+	errs := make([]error, 0, len(outs))
+	for _, o := range outs {
+		if o.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", o.Path, o.Err))
+		}
+	}
+	if len(errs) == len(outs) {
+		errs = append([]error{ErrAllOutputsFailed}, errs...)
+	}
+	return errors.Join(errs...)
+}
+
+func (outs outputs) label() string {
+	if len(outs) == 1 {
+		return "File"
+	}
+	return "Files"
+}
+
+// summary lists each output path, along with its error if it failed.
+func (outs outputs) summary(r *lipgloss.Renderer) string {
+	lines := make([]string, 0, len(outs))
+	for _, o := range outs {
+		line := tui.OutPath(o.Path, r)
+		if parts := o.Parts(); parts != 0 {
+			line += fmt.Sprintf(" (%d parts)", parts)
+		}
+		if o.Err != nil && len(outs) > 1 {
+			line += " " + tui.ErrStyle(r).Render("("+o.Err.Error()+")")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,99 @@
+package dump
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWrite = errors.New("write failed")
+
+type errWriteCloser struct{}
+
+func (errWriteCloser) Write([]byte) (int, error) { return 0, errWrite }
+func (errWriteCloser) Close() error              { return nil }
+
+func TestOutputs(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := openOutput(t.Context(), filepath.Join(dir, "a.sql"), 0, storage.PutOptions{})
+	require.NoError(t, err)
+	b, err := openOutput(t.Context(), filepath.Join(dir, "nested", "b.sql"), 0, storage.PutOptions{})
+	require.NoError(t, err)
+	failed := &output{Path: "failed.sql", w: errWriteCloser{}}
+	outs := outputs{a, failed, b}
+
+	_, err = io.WriteString(outs, "SELECT 1;")
+	require.NoError(t, err)
+	require.ErrorIs(t, failed.Err, errWrite)
+
+	for _, o := range outs {
+		_ = o.finish()
+	}
+
+	for _, path := range []string{a.Path, b.Path} {
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1;", string(got))
+	}
+
+	err = outs.Err()
+	require.ErrorIs(t, err, errWrite)
+	require.NotErrorIs(t, err, ErrAllOutputsFailed)
+	assert.Contains(t, err.Error(), "failed.sql")
+}
+
+func TestOutputs_AllFailed(t *testing.T) {
+	outs := outputs{
+		&output{Path: "a.sql", w: errWriteCloser{}},
+		&output{Path: "b.sql", w: errWriteCloser{}},
+	}
+
+	_, err := io.WriteString(outs, "SELECT 1;")
+	require.ErrorIs(t, err, ErrAllOutputsFailed)
+	require.ErrorIs(t, err, errWrite)
+}
+
+func TestOutput_cleanup(t *testing.T) {
+	dir := t.TempDir()
+
+	o, err := openOutput(t.Context(), filepath.Join(dir, "a.sql"), 0, storage.PutOptions{})
+	require.NoError(t, err)
+	_, err = io.WriteString(o.w, "SELECT")
+	require.NoError(t, err)
+
+	o.cleanup(nil)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestOutput_split(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.sql")
+
+	o, err := openOutput(t.Context(), path, 4, storage.PutOptions{})
+	require.NoError(t, err)
+	_, err = io.WriteString(o.w, "SELECT 1;")
+	require.NoError(t, err)
+	require.NoError(t, o.finish())
+	assert.Equal(t, 3, o.Parts())
+
+	for i, want := range []string{"SELE", "CT 1", ";"} {
+		got, err := os.ReadFile(path + ".part00" + strconv.Itoa(i+1))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
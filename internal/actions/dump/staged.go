@@ -0,0 +1,54 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/clevyr/kubedb/internal/staging"
+	"github.com/clevyr/kubedb/internal/util"
+)
+
+const stagedFile = util.StageDir + "/dump"
+
+var (
+	ErrStagedJob           = errors.New("staged dumps require a job pod")
+	ErrStagedClusterUpload = errors.New("staged dumps cannot be uploaded from the cluster")
+)
+
+// ValidateStaged checks that the dump can be staged in the job pod.
+func (action Dump) ValidateStaged() error {
+	if !action.CreateJob {
+		return ErrStagedJob
+	}
+	if action.ClusterUpload.Enabled {
+		return ErrStagedClusterUpload
+	}
+	return nil
+}
+
+// runStaged writes the dump to a file in the job pod, then downloads it to w.
+// The dump runs in the background of the pod, so it continues if the connection drops.
+// The download resumes from the last received offset.
+func (action Dump) runStaged(ctx context.Context, w io.Writer, logs io.Writer) error {
+	cmd, err := action.buildCommand()
+	if err != nil {
+		return err
+	}
+
+	f := staging.New(action.Client, action.JobPod, util.JobContainer, stagedFile, logs)
+	slog.Info("Staging dump in job pod", "pod", action.JobPod.Name)
+	if err := f.Create(ctx, cmd); err != nil {
+		return err
+	}
+
+	want, err := f.Stat(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Downloading staged dump", "size", bytefmt.Encode(want.Size))
+	return f.Download(ctx, w, want)
+}
@@ -0,0 +1,35 @@
+package dump
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump_ValidateStaged(t *testing.T) {
+	tests := []struct {
+		name          string
+		createJob     bool
+		clusterUpload bool
+		wantErr       error
+	}{
+		{"job", true, false, nil},
+		{"no job", false, false, ErrStagedJob},
+		{"cluster upload", true, true, ErrStagedClusterUpload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := Dump{Dump: conftypes.Dump{Global: &conftypes.Global{
+				CreateJob:     tt.createJob,
+				ClusterUpload: conftypes.ClusterUpload{Enabled: tt.clusterUpload},
+			}}}
+			err := action.ValidateStaged()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
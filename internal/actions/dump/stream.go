@@ -0,0 +1,63 @@
+package dump
+
+import (
+	"bufio"
+	"io"
+
+	"filippo.io/age"
+	"github.com/clevyr/kubedb/internal/compression"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
+)
+
+// stream converts the dump from the database into the output format.
+type stream struct {
+	Format sqlformat.Format
+	// Compressed is true if the dump may be compressed over the wire
+	Compressed bool
+	Recipients []age.Recipient
+}
+
+// write converts r to the output format, then writes it to outs and w.
+// It is encrypted first if recipients are set. The number of bytes read from r is returned.
+func (s stream) write(r io.Reader, outs outputs, w ...io.Writer) (int64, error) {
+	if s.Format != sqlformat.Custom {
+		// Convert from the wire compression to the output format
+		wire := compression.None
+		if s.Compressed {
+			br := bufio.NewReader(r)
+			wire = compression.Detect(br)
+			r = br
+		}
+
+		converted, err := compression.Convert(r, wire, compression.FromFormat(s.Format))
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			_ = converted.Close()
+		}()
+		r = converted
+	}
+
+	dst := io.Writer(io.MultiWriter(append([]io.Writer{outs}, w...)...))
+	if len(s.Recipients) != 0 {
+		enc, err := encryption.Encrypt(dst, s.Recipients...)
+		if err != nil {
+			return 0, err
+		}
+		dst = enc
+	}
+
+	n, err := io.Copy(dst, r) //nolint:gosec
+	if err != nil {
+		return n, err
+	}
+	if enc, ok := dst.(io.Closer); ok {
+		// Flush the final encrypted chunk
+		if err := enc.Close(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
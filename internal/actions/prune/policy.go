@@ -0,0 +1,94 @@
+package prune
+
+import (
+	"strconv"
+
+	"github.com/clevyr/kubedb/internal/backups"
+)
+
+// Policy is a grandfather-father-son retention policy.
+// Each rule is applied separately to every namespace and database.
+type Policy struct {
+	Last    int
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+func (p Policy) IsZero() bool {
+	return p.Last <= 0 && p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0
+}
+
+type Decision struct {
+	*backups.Backup
+	Reasons []string
+}
+
+func (d Decision) Keep() bool {
+	return len(d.Reasons) != 0
+}
+
+type bucket struct {
+	limit int
+	count int
+	last  string
+	key   func(*backups.Backup) string
+}
+
+func (b *bucket) keep(backup *backups.Backup) bool {
+	if b.count >= b.limit {
+		return false
+	}
+	if key := b.key(backup); key != b.last {
+		b.count++
+		b.last = key
+		return true
+	}
+	return false
+}
+
+// Apply decides which backups to keep. The list must be sorted newest first.
+func (p Policy) Apply(list []*backups.Backup) []Decision {
+	type state struct {
+		count                  int
+		daily, weekly, monthly *bucket
+	}
+	states := make(map[backups.Group]*state)
+
+	decisions := make([]Decision, 0, len(list))
+	for _, backup := range list {
+		s, ok := states[backup.Group()]
+		if !ok {
+			s = &state{
+				daily: &bucket{limit: p.Daily, key: func(b *backups.Backup) string {
+					return b.Date.Format("2006-01-02")
+				}},
+				weekly: &bucket{limit: p.Weekly, key: func(b *backups.Backup) string {
+					year, week := b.Date.ISOWeek()
+					return strconv.Itoa(year) + "-" + strconv.Itoa(week)
+				}},
+				monthly: &bucket{limit: p.Monthly, key: func(b *backups.Backup) string {
+					return b.Date.Format("2006-01")
+				}},
+			}
+			states[backup.Group()] = s
+		}
+
+		decision := Decision{Backup: backup}
+		if s.count < p.Last {
+			s.count++
+			decision.Reasons = append(decision.Reasons, "last")
+		}
+		if s.daily.keep(backup) {
+			decision.Reasons = append(decision.Reasons, "daily")
+		}
+		if s.weekly.keep(backup) {
+			decision.Reasons = append(decision.Reasons, "weekly")
+		}
+		if s.monthly.keep(backup) {
+			decision.Reasons = append(decision.Reasons, "monthly")
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions
+}
@@ -0,0 +1,85 @@
+package prune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/backups"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBackups(namespace string, dates ...time.Time) []*backups.Backup {
+	list := make([]*backups.Backup, 0, len(dates))
+	for _, date := range dates {
+		list = append(list, &backups.Backup{
+			Filename: dump.Filename{Namespace: namespace, Ext: ".sql.gz", Date: date},
+		})
+	}
+	return list
+}
+
+func reasons(decisions []Decision) [][]string {
+	result := make([][]string, 0, len(decisions))
+	for _, d := range decisions {
+		result = append(result, d.Reasons)
+	}
+	return result
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name   string
+		policy Policy
+		list   []*backups.Backup
+		want   [][]string
+	}{
+		{
+			"keep last",
+			Policy{Last: 2},
+			newBackups("test", day(3, 0), day(2, 0), day(1, 0)),
+			[][]string{{"last"}, {"last"}, nil},
+		},
+		{
+			"keep daily",
+			Policy{Daily: 2},
+			newBackups("test", day(3, 12), day(3, 0), day(2, 12), day(2, 0), day(1, 0)),
+			[][]string{{"daily"}, nil, {"daily"}, nil, nil},
+		},
+		{
+			"keep weekly",
+			Policy{Weekly: 2},
+			// Mar 11 and 10 are in different ISO weeks
+			newBackups("test", day(11, 0), day(10, 0), day(4, 0), day(1, 0)),
+			[][]string{{"weekly"}, {"weekly"}, nil, nil},
+		},
+		{
+			"keep monthly",
+			Policy{Monthly: 2},
+			append(
+				newBackups("test", day(2, 0), day(1, 0)),
+				newBackups("test", time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local), time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))...,
+			),
+			[][]string{{"monthly"}, nil, {"monthly"}, nil},
+		},
+		{
+			"combined",
+			Policy{Last: 1, Daily: 2, Monthly: 1},
+			newBackups("test", day(3, 12), day(3, 0), day(2, 0)),
+			[][]string{{"last", "daily", "monthly"}, nil, {"daily"}},
+		},
+		{
+			"groups are separate",
+			Policy{Last: 1},
+			append(newBackups("a", day(3, 0), day(2, 0)), newBackups("b", day(1, 0))...),
+			[][]string{{"last"}, nil, {"last"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reasons(tt.policy.Apply(tt.list)))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package prune
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/backups"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/tui"
+)
+
+var ErrNoPolicy = errors.New("at least one keep rule is required")
+
+type Prune struct {
+	conftypes.Prune `koanf:",squash"`
+}
+
+func (action Prune) Policy() Policy {
+	return Policy{
+		Last:    action.KeepLast,
+		Daily:   action.KeepDaily,
+		Weekly:  action.KeepWeekly,
+		Monthly: action.KeepMonthly,
+	}
+}
+
+// Plan lists the backups in Dir and decides which ones to keep.
+func (action Prune) Plan(ctx context.Context) ([]Decision, error) {
+	policy := action.Policy()
+	if policy.IsZero() {
+		return nil, ErrNoPolicy
+	}
+
+	tmpl, err := dump.NewFilenameTemplate(action.FilenameTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := backups.List(ctx, action.Dir, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := policy.Apply(list)
+	slices.SortStableFunc(decisions, func(a, b Decision) int {
+		return backups.CompareGroup(a.Group(), b.Group())
+	})
+	return decisions, nil
+}
+
+func (action Prune) Table(r *lipgloss.Renderer, decisions []Decision) *tui.Table {
+	if r == nil {
+		r = tui.Renderer
+	}
+	keepStyle := lipgloss.NewStyle().Renderer(r).Foreground(tui.ColorGreen)
+	deleteStyle := tui.ErrStyle(r)
+
+	t := tui.ListTable(r, "Namespace", "Database", "Date", "Size", "Action")
+	for _, d := range decisions {
+		status := deleteStyle.Render("delete")
+		if d.Keep() {
+			status = keepStyle.Render("keep (" + strings.Join(d.Reasons, ", ") + ")")
+		}
+
+		t.Row(
+			tui.NamespaceStyle(r, action.NamespaceColors, d.Namespace).Render(),
+			d.Group().Database,
+			d.Date.Format("2006-01-02 15:04:05"),
+			bytefmt.Encode(d.Size),
+			status,
+		)
+	}
+	return t
+}
+
+func (action Prune) Confirm(decisions []Decision) (bool, error) {
+	var count int
+	for _, d := range decisions {
+		if !d.Keep() {
+			count++
+		}
+	}
+
+	theme := huh.ThemeCharm()
+	theme.Focused.Description = tui.TextStyle(nil)
+
+	var response bool
+	err := tui.NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(fmt.Sprintf("Delete %d backups from %s?", count, tui.InPath(action.Dir, nil))).
+			Value(&response),
+	)).WithTheme(theme).Run()
+	return response, err
+}
+
+// Run deletes every backup which was not kept by the policy.
+func (action Prune) Run(ctx context.Context, decisions []Decision) error {
+	var client storage.Client
+	if storage.IsCloud(action.Dir) {
+		var err error
+		if client, err = storage.NewClient(ctx, action.Dir); err != nil {
+			return err
+		}
+	}
+
+	var deleted, kept int
+	var errs []error
+	for _, d := range decisions {
+		if d.Keep() {
+			kept++
+			continue
+		}
+
+		paths := []string{d.Path}
+		if len(d.Parts) != 0 {
+			paths = slices.Clone(d.Parts)
+		}
+		if d.HasManifest {
+			paths = append(paths, manifest.Path(d.Path))
+		}
+
+		var err error
+		for _, p := range paths {
+			if client != nil {
+				err = client.DeleteObject(ctx, p)
+			} else {
+				err = os.Remove(p)
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			slog.Error("Failed to delete backup", "file", d.Path, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", path.Base(d.Path), err))
+			continue
+		}
+
+		slog.Info("Deleted backup", "file", d.Path)
+		deleted++
+	}
+
+	slog.Info("Prune complete", "deleted", deleted, "kept", kept)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package restore
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
+)
+
+// input is an opened dump file. Split volumes are read back as a single stream.
+type input struct {
+	io.ReadCloser
+
+	// Path has the volume extension removed
+	Path  string
+	Parts int
+	// Size is -1 when unknown
+	Size int64
+}
+
+// openInput opens the dump at p. If p is a split volume, or if only volumes exist for p,
+// then every volume is read in order.
+func openInput(ctx context.Context, client storage.Client, p string) (*input, error) {
+	base, _, isPart := split.Trim(p)
+	if !isPart {
+		r, size, err := openFile(ctx, client, p)
+		if err == nil {
+			return &input{ReadCloser: r, Path: p, Size: size}, nil
+		}
+
+		// Fall back to volumes if the file does not exist
+		if volumes, listErr := listVolumes(ctx, client, p); listErr != nil || len(volumes) == 0 {
+			return nil, err
+		}
+	}
+
+	volumes, err := listVolumes(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+	if err := split.Check(base, slices.Collect(maps.Keys(volumes)), 0); err != nil {
+		return nil, err
+	}
+
+	size := int64(-1)
+	if client != nil {
+		size = 0
+		for _, s := range volumes {
+			size += s
+		}
+	}
+
+	r := split.NewReader(len(volumes), func(n int) (io.ReadCloser, error) {
+		r, _, err := openFile(ctx, client, split.Path(base, n))
+		return r, err
+	})
+	return &input{ReadCloser: r, Path: base, Parts: len(volumes), Size: size}, nil
+}
+
+func openFile(ctx context.Context, client storage.Client, p string) (io.ReadCloser, int64, error) {
+	if client != nil {
+		f, err := client.GetObject(ctx, p)
+		if err != nil {
+			return nil, 0, err
+		}
+		if sized, ok := f.(interface{ Size() int64 }); ok {
+			return f, sized.Size(), nil
+		}
+		return f, -1, nil
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	return f, -1, nil
+}
+
+// listVolumes finds the volumes of base, mapping each volume number to its size.
+func listVolumes(ctx context.Context, client storage.Client, base string) (map[int]int64, error) {
+	volumes := make(map[int]int64)
+	if client == nil {
+		entries, err := os.ReadDir(filepath.Dir(base))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := filepath.Join(filepath.Dir(base), entry.Name())
+			if b, n, ok := split.Trim(name); ok && b == filepath.Clean(base) {
+				info, err := entry.Info()
+				if err != nil {
+					return nil, err
+				}
+				volumes[n] = info.Size()
+			}
+		}
+		return volumes, nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	u.RawQuery = ""
+	if u.Path = path.Dir(u.Path); u.Path != "/" {
+		u.Path += "/"
+	}
+
+	for object, err := range client.ListObjects(ctx, u.String()) {
+		if err != nil {
+			return nil, err
+		}
+		if b, n, ok := split.Trim(object.Name); ok && b == key {
+			volumes[n] = object.Size
+		}
+	}
+	return volumes, nil
+}
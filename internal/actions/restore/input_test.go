@@ -0,0 +1,93 @@
+package restore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/storage/sftptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenInput(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "test_2024-03-05_130405.sql")
+	for i, content := range []string{"SELE", "CT 1", ";"} {
+		require.NoError(t, os.WriteFile(split.Path(base, i+1), []byte(content), 0o600))
+	}
+	plain := filepath.Join(dir, "plain.sql")
+	require.NoError(t, os.WriteFile(plain, []byte("SELECT 2;"), 0o600))
+
+	tests := []struct {
+		name      string
+		path      string
+		wantPath  string
+		wantParts int
+		want      string
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{"plain", plain, plain, 0, "SELECT 2;", require.NoError},
+		{"first part", split.Path(base, 1), base, 3, "SELECT 1;", require.NoError},
+		{"prefix", base, base, 3, "SELECT 1;", require.NoError},
+		{"missing", filepath.Join(dir, "missing.sql"), "", 0, "", require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := openInput(t.Context(), nil, tt.path)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() {
+				_ = in.Close()
+			})
+
+			assert.Equal(t, tt.wantPath, in.Path)
+			assert.Equal(t, tt.wantParts, in.Parts)
+			got, err := io.ReadAll(in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestOpenInput_MissingPart(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "test_2024-03-05_130405.sql")
+	for _, n := range []int{1, 3} {
+		require.NoError(t, os.WriteFile(split.Path(base, n), nil, 0o600))
+	}
+
+	_, err := openInput(t.Context(), nil, base)
+	require.ErrorIs(t, err, split.ErrMissingPart)
+}
+
+func Test_listVolumes(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "test_2024-03-05_130405.sql")
+	for i, content := range []string{"SELE", "CT 1", ";"} {
+		require.NoError(t, os.WriteFile(split.Path(base, i+1), []byte(content), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.sql.part001"), nil, 0o600))
+	want := map[int]int64{1: 4, 2: 4, 3: 1}
+
+	t.Run("local", func(t *testing.T) {
+		got, err := listVolumes(t.Context(), nil, base)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("sftp", func(t *testing.T) {
+		u := "sftp://test@" + sftptest.NewServer(t) + base
+		client, err := storage.NewClient(t.Context(), u)
+		require.NoError(t, err)
+
+		got, err := listVolumes(t.Context(), client, u)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+}
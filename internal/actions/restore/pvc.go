@@ -0,0 +1,262 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
+	"github.com/clevyr/kubedb/internal/finalizer"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/util"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrPVCJob       = errors.New("restoring from a pvc requires a job pod")
+	ErrPVCEncrypted = errors.New("encrypted dumps cannot be restored from a pvc")
+)
+
+// pvcInput is a dump on a claim. Split volumes are listed in order.
+type pvcInput struct {
+	// Path has the volume extension removed
+	Path  string
+	Files []string
+	Size  int64
+}
+
+// findPVCInput finds the files of the dump at p.
+func findPVCInput(ctx context.Context, client storage.Client, p string) (*pvcInput, error) {
+	base, _, isPart := split.Trim(p)
+	if !isPart {
+		u, err := url.Parse(p)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.TrimPrefix(u.Path, "/")
+		for object, err := range client.ListObjects(ctx, p) {
+			if err != nil {
+				return nil, err
+			}
+			if object.Name == key && !object.IsDir {
+				return &pvcInput{Path: p, Files: []string{p}, Size: object.Size}, nil
+			}
+		}
+	}
+
+	volumes, err := listVolumes(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("%w: %s", fs.ErrNotExist, p)
+	}
+	if err := split.Check(base, slices.Collect(maps.Keys(volumes)), 0); err != nil {
+		return nil, err
+	}
+
+	in := &pvcInput{Path: base, Files: make([]string, 0, len(volumes))}
+	for n := 1; n <= len(volumes); n++ {
+		in.Files = append(in.Files, split.Path(base, n))
+		in.Size += volumes[n]
+	}
+	return in, nil
+}
+
+// pvcDecompress returns the command which decompresses a dump in the given format.
+func pvcDecompress(format sqlformat.Format) []any {
+	switch format {
+	case sqlformat.Gzip, sqlformat.Unknown:
+		return []any{command.Pipe, "gzip", "--decompress", "--stdout", "--force"}
+	case sqlformat.Zstd:
+		return []any{command.Pipe, "zstd", "--decompress", "--stdout"}
+	default:
+		return nil
+	}
+}
+
+// runFromPVC restores a dump from a claim which is mounted in the job pod,
+// so the dump is never sent through this machine.
+//
+//nolint:funlen
+func (action Restore) runFromPVC(ctx context.Context) error {
+	claim, err := storage.PVCClaim(action.Input)
+	if err != nil {
+		return err
+	}
+	client := storage.NewPVCInPod(action.Client, action.JobPod, util.JobContainer, claim, util.PVCMountPath(claim))
+
+	in, err := findPVCInput(ctx, client, action.Input)
+	if err != nil {
+		return err
+	}
+	action.Input = in.Path
+
+	files := make([]string, 0, len(in.Files))
+	for _, f := range in.Files {
+		p, err := storage.PVCPath(f)
+		if err != nil {
+			return err
+		}
+		files = append(files, p)
+	}
+
+	actionLog := slog.With(
+		"file", action.Input,
+		"parts", len(in.Files),
+		"namespace", action.Client.Namespace,
+		"pod", action.DBPod.Name,
+	)
+
+	if encrypted, err := action.pvcEncrypted(ctx, client, in.Files[0]); err != nil {
+		return err
+	} else if encrypted {
+		return fmt.Errorf("%w: %s", ErrPVCEncrypted, action.Input)
+	}
+
+	if m, err := manifest.Read(ctx, client, action.Input); err == nil {
+		if len(in.Files) > 1 && m.Parts > len(in.Files) {
+			return fmt.Errorf("%w: %s", split.ErrMissingPart, split.Path(action.Input, len(in.Files)+1))
+		}
+
+		actionLog.Info("Verifying checksum from manifest")
+		if err := action.verifyPVC(ctx, m, files); err != nil {
+			return err
+		}
+	} else {
+		actionLog.Debug("Skipping checksum verification", "error", err)
+	}
+
+	// The dump is read directly from the claim
+	action.RemoteGzip = false
+	action.RemoteZstd = false
+	restoreCmd, err := action.buildCommand(action.Format)
+	if err != nil {
+		return err
+	}
+
+	cmd := command.NewBuilder(command.Raw("{"))
+	if action.Clean && action.Format != sqlformat.Custom {
+		if db, ok := action.Dialect.(conftypes.DBDatabaseDropper); ok {
+			actionLog.Info("Cleaning existing data")
+			cmd.Push("printf", "%s", db.DatabaseDropQuery(action.Database), command.Raw(";"))
+		}
+	}
+	cmd.Push("cat")
+	for _, f := range files {
+		cmd.Push(f)
+	}
+	cmd.Push(pvcDecompress(action.Format)...)
+	var analyze string
+	if action.Analyze {
+		if db, ok := action.Dialect.(conftypes.DBAnalyzer); ok {
+			analyze = db.AnalyzeQuery()
+			if action.Format != sqlformat.Custom {
+				cmd.Push(command.Raw(";"), "printf", "%s", analyze)
+				analyze = ""
+			}
+		}
+	}
+	cmd.Push(command.Raw("; }"), command.Pipe, command.Raw(restoreCmd.String()))
+
+	actionLog.Info("Restoring database")
+
+	startTime := time.Now()
+	bar := progressbar.New(os.Stderr, -1, "restoring", action.Progress, action.Spinner)
+	defer bar.Close()
+
+	finalizer.Add(func(err error) {
+		action.printSummary(err, time.Since(startTime).Truncate(10*time.Millisecond), in.Size)
+	})
+
+	if err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+		Pod:         action.JobPod,
+		Cmd:         cmd.String(),
+		Stdout:      bar.Logger(),
+		Stderr:      bar.Logger(),
+		DisablePing: true,
+	}); err != nil {
+		return err
+	}
+
+	if analyze != "" {
+		// Custom dumps are restored by a separate client, so analyze with the plain client
+		errGroup, ctx := errgroup.WithContext(ctx)
+		pr, pw := io.Pipe()
+		errGroup.Go(func() error {
+			return action.runInDatabasePod(ctx, pr, bar.Logger(), bar.Logger(), sqlformat.Gzip)
+		})
+		errGroup.Go(func() error {
+			_, err := action.copy(pw, strings.NewReader(analyze))
+			_ = pw.CloseWithError(err)
+			return err
+		})
+		if err := errGroup.Wait(); err != nil {
+			return err
+		}
+	}
+
+	_ = bar.Finish()
+
+	action.complete(ctx, time.Since(startTime), in.Size)
+	return nil
+}
+
+// pvcEncrypted returns true if the dump on the claim is encrypted.
+func (action Restore) pvcEncrypted(ctx context.Context, client storage.Client, p string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r, err := client.GetObject(ctx, p)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	return encryption.IsEncrypted(bufio.NewReader(r)), nil
+}
+
+// verifyPVC compares the checksum of the files on the claim with the manifest.
+func (action Restore) verifyPVC(ctx context.Context, m *manifest.Manifest, files []string) error {
+	cmd := command.NewBuilder("cat")
+	for _, f := range files {
+		cmd.Push(f)
+	}
+	cmd.Push(command.Pipe, "sha256sum")
+
+	var stdout bytes.Buffer
+	if err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+		Pod:         action.JobPod,
+		Cmd:         cmd.String(),
+		Stdout:      &stdout,
+		DisablePing: true,
+	}); err != nil {
+		return err
+	}
+
+	sum, _, _ := strings.Cut(stdout.String(), " ")
+	if sum != m.SHA256 {
+		return fmt.Errorf("%w: expected sha256 %s, got %s", manifest.ErrChecksumMismatch, m.SHA256, sum)
+	}
+	return nil
+}
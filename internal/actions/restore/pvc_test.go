@@ -0,0 +1,68 @@
+package restore
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listClient struct {
+	storage.Client
+	objects []*storage.Object
+}
+
+func (l listClient) ListObjects(_ context.Context, key string) iter.Seq2[*storage.Object, error] {
+	return func(yield func(*storage.Object, error) bool) {
+		for _, object := range l.objects {
+			if !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
+
+func Test_findPVCInput(t *testing.T) {
+	client := listClient{objects: []*storage.Object{
+		{Name: "db/plain.sql", Size: 9},
+		{Name: "db/split.sql.gz.part001", Size: 4},
+		{Name: "db/split.sql.gz.part002", Size: 2},
+		{Name: "db/gap.sql.part001", Size: 1},
+		{Name: "db/gap.sql.part003", Size: 1},
+	}}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    *pvcInput
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"plain", "pvc://backups/db/plain.sql", &pvcInput{
+			Path:  "pvc://backups/db/plain.sql",
+			Files: []string{"pvc://backups/db/plain.sql"},
+			Size:  9,
+		}, require.NoError},
+		{"split", "pvc://backups/db/split.sql.gz", &pvcInput{
+			Path:  "pvc://backups/db/split.sql.gz",
+			Files: []string{"pvc://backups/db/split.sql.gz.part001", "pvc://backups/db/split.sql.gz.part002"},
+			Size:  6,
+		}, require.NoError},
+		{"first part", "pvc://backups/db/split.sql.gz.part001", &pvcInput{
+			Path:  "pvc://backups/db/split.sql.gz",
+			Files: []string{"pvc://backups/db/split.sql.gz.part001", "pvc://backups/db/split.sql.gz.part002"},
+			Size:  6,
+		}, require.NoError},
+		{"missing part", "pvc://backups/db/gap.sql", nil, require.Error},
+		{"missing", "pvc://backups/db/missing.sql", nil, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findPVCInput(t.Context(), client, tt.path)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
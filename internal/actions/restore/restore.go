@@ -1,8 +1,9 @@
 package restore
 
 import (
-	"compress/gzip"
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -15,13 +16,20 @@ import (
 	"gabe565.com/utils/slogx"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/backups"
 	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/compression"
 	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
 	"github.com/clevyr/kubedb/internal/finalizer"
 	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/manifest"
 	"github.com/clevyr/kubedb/internal/notifier"
 	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/ratelimit"
+	"github.com/clevyr/kubedb/internal/split"
 	"github.com/clevyr/kubedb/internal/storage"
 	"github.com/clevyr/kubedb/internal/tui"
 	"github.com/clevyr/kubedb/internal/util"
@@ -33,66 +41,137 @@ type Restore struct {
 	conftypes.Restore `koanf:",squash"`
 
 	Analyze bool
+
+	remoteZstd bool
 }
 
 func (action Restore) Run(ctx context.Context) error { //nolint:gocognit
+	if storage.IsPVC(action.Input) {
+		return action.runFromPVC(ctx)
+	}
+
 	errGroup, ctx := errgroup.WithContext(ctx)
 
+	limit, err := ratelimit.Parse(action.LimitRate)
+	if err != nil {
+		return err
+	}
+	limiter := ratelimit.NewLimiter(limit)
+
 	var f io.ReadCloser
-	switch {
-	case action.Input == "-":
+	var client storage.Client
+	size := int64(-1)
+	var parts int
+	if action.Input == "-" {
 		f = os.Stdin
-	case storage.IsCloud(action.Input):
-		client, err := storage.NewClient(ctx, action.Input)
-		if err != nil {
-			return err
+	} else {
+		if storage.IsCloud(action.Input) || storage.IsHTTP(action.Input) {
+			if client, err = storage.NewClient(ctx, action.Input); err != nil {
+				return err
+			}
 		}
 
-		f, err = client.GetObject(ctx, action.Input)
+		in, err := openInput(ctx, client, action.Input)
 		if err != nil {
 			return err
 		}
 		defer func(f io.ReadCloser) {
 			_ = f.Close()
-		}(f)
-	default:
-		var err error
-		if f, err = os.Open(action.Input); err != nil {
-			return err
-		}
-		defer func(f io.ReadCloser) {
-			_ = f.Close()
-		}(f)
+		}(in)
+
+		f = in
+		size = in.Size
+		parts = in.Parts
+		action.Input = in.Path
 	}
 
 	actionLog := slog.With(
 		"file", action.Input,
+		"parts", parts,
 		"namespace", action.Client.Namespace,
 		"pod", action.DBPod.Name,
 	)
 
+	var verifier io.Reader
+	if action.Input != "-" {
+		if m, err := manifest.Read(ctx, client, action.Input); err == nil {
+			if parts != 0 && m.Parts > parts {
+				return fmt.Errorf("%w: %s", split.ErrMissingPart, split.Path(action.Input, parts+1))
+			}
+
+			actionLog.Info("Verifying checksum from manifest")
+			verifier = m.NewVerifier(f)
+			f = io.NopCloser(verifier)
+		} else {
+			actionLog.Debug("Skipping checksum verification", "error", err)
+		}
+	}
+
 	actionLog.Info("Ready to restore database")
 
 	startTime := time.Now()
-	bar := progressbar.New(os.Stderr, -1, "uploading", action.Progress, action.Spinner)
+	bar := progressbar.New(os.Stderr, size, "uploading", action.Progress, action.Spinner)
 	defer bar.Close()
 
+	if size >= 0 {
+		// Track progress against the source size instead of the bytes sent to the pod
+		f = io.NopCloser(io.TeeReader(f, bar))
+	}
+
+	if br := bufio.NewReader(f); encryption.IsEncrypted(br) {
+		identities, err := encryption.ParseIdentities(action.Identities, action.Passphrase)
+		if err != nil {
+			return err
+		}
+
+		actionLog.Info("Decrypting file")
+		r, err := encryption.Decrypt(br, identities...)
+		if err != nil {
+			return err
+		}
+		f = io.NopCloser(r)
+	} else {
+		f = io.NopCloser(br)
+	}
+
+	if action.RemoteZstd {
+		action.remoteZstd = action.hasRemoteZstd(ctx)
+		if !action.remoteZstd {
+			actionLog.Warn("zstd is not installed in the pod. Falling back to gzip.")
+		}
+	}
+
+	execCtx, cancelExec := context.WithCancelCause(ctx)
+	defer cancelExec(nil)
+
 	pr, pw := io.Pipe()
 	errGroup.Go(func() error {
 		// Connect to pod and begin piping from io.PipeReader
 		defer func(pr io.ReadCloser) {
 			_ = pr.Close()
 		}(pr)
-		return action.runInDatabasePod(ctx, pr, bar.Logger(), bar.Logger(), action.Format)
+		if action.Staged {
+			return action.runStaged(execCtx, pr, bar)
+		}
+		return action.runInDatabasePod(execCtx, pr, bar.Logger(), bar.Logger(), action.Format)
 	})
 
 	var written atomic.Int64
-	errGroup.Go(func() error {
-		defer func(pw io.WriteCloser) {
-			_ = pw.Close()
+	errGroup.Go(func() (err error) {
+		defer func(pw *io.PipeWriter) {
+			if err != nil {
+				// Kill the remote command first, so it does not see EOF and finish the restore.
+				// Statements which already ran, like those before a checksum mismatch, are not undone.
+				cancelExec(err)
+			}
+			// Pass errors through so the pod does not see a clean EOF
+			_ = pw.CloseWithError(err)
 		}(pw)
 
-		w := io.MultiWriter(pw, bar)
+		w := ratelimit.NewWriter(ctx, pw, limiter)
+		if size < 0 {
+			w = io.MultiWriter(w, bar)
+		}
 
 		// Clean database
 		if action.Clean && action.Format != sqlformat.Custom {
@@ -109,27 +188,27 @@ func (action Restore) Run(ctx context.Context) error { //nolint:gocognit
 
 		// Main restore
 		actionLog.Info("Restoring database")
-		switch action.Format {
-		case sqlformat.Gzip, sqlformat.Unknown:
-			if !action.RemoteGzip {
-				var err error
-				if f, err = gzip.NewReader(f); err != nil {
-					return err
-				}
-				defer func(f io.ReadCloser) {
-					_ = f.Close()
-				}(f)
-			}
+		source := compression.FromFormat(action.Format)
+		if action.Format == sqlformat.Unknown {
+			source = compression.Gzip
+		}
+		converted, err := compression.Convert(f, source, action.wireCompression())
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = converted.Close()
+		}()
 
-			n, err := io.Copy(w, f) //nolint:gosec
-			written.Add(n)
-			if err != nil {
-				return err
-			}
-		case sqlformat.Plain, sqlformat.Custom:
-			n, err := action.copy(w, f)
-			written.Add(n)
-			if err != nil {
+		n, err := io.Copy(w, converted) //nolint:gosec
+		written.Add(n)
+		if err != nil {
+			return err
+		}
+
+		if verifier != nil {
+			// Readers may stop before EOF, so make sure the whole file was verified
+			if _, err := io.Copy(io.Discard, verifier); err != nil {
 				return err
 			}
 		}
@@ -138,7 +217,10 @@ func (action Restore) Run(ctx context.Context) error { //nolint:gocognit
 		if action.Analyze {
 			if db, ok := action.Dialect.(conftypes.DBAnalyzer); ok {
 				analyzeQuery := db.AnalyzeQuery()
-				if action.Format == sqlformat.Custom {
+				switch {
+				case action.Format == sqlformat.Custom && action.Staged:
+					// Staged restores analyze after pg_restore finishes
+				case action.Format == sqlformat.Custom:
 					defer func() {
 						pr, pw := io.Pipe()
 
@@ -158,7 +240,7 @@ func (action Restore) Run(ctx context.Context) error { //nolint:gocognit
 							return err
 						})
 					}()
-				} else {
+				default:
 					n, err := action.copy(w, strings.NewReader(analyzeQuery))
 					written.Add(n)
 					if err != nil {
@@ -186,19 +268,26 @@ func (action Restore) Run(ctx context.Context) error { //nolint:gocognit
 
 	_ = bar.Finish()
 
-	actionLog.Info("Restore complete",
-		"took", time.Since(startTime).Truncate(10*time.Millisecond),
-		"size", bytefmt.Encode(written.Load()),
+	action.complete(ctx, time.Since(startTime), written.Load())
+	return nil
+}
+
+// complete logs the result and passes the summary to the notifier.
+func (action Restore) complete(ctx context.Context, took time.Duration, written int64) {
+	took = took.Truncate(10 * time.Millisecond)
+	slog.Info("Restore complete",
+		"file", action.Input,
+		"namespace", action.Client.Namespace,
+		"pod", action.DBPod.Name,
+		"took", took,
+		"size", bytefmt.Encode(written),
 	)
 
 	if handler, ok := notifier.FromContext(ctx); ok {
 		if logger, ok := handler.(notifier.Logs); ok {
-			logger.SetLog(
-				action.summary(nil, time.Since(startTime).Truncate(10*time.Millisecond), written.Load(), true),
-			)
+			logger.SetLog(action.summary(nil, took, written, true))
 		}
 	}
-	return nil
 }
 
 func (action Restore) buildCommand(inputFormat sqlformat.Format) (*command.Builder, error) {
@@ -214,25 +303,50 @@ func (action Restore) buildCommand(inputFormat sqlformat.Format) (*command.Build
 	cmd.Unshift(command.Raw("{"))
 	cmd.Push(command.Raw("|| { cat >/dev/null; kill $$; }; }"))
 
-	if action.RemoteGzip {
+	switch action.wireCompression() {
+	case compression.Gzip:
 		cmd.Unshift("gunzip", "--force", command.Pipe)
+	case compression.Zstd:
+		cmd.Unshift("zstd", "--decompress", "--stdout", command.Pipe)
 	}
 	slogx.Trace("Finished building command", "cmd", cmd)
 	return cmd, nil
 }
 
 func (action Restore) copy(w io.Writer, r io.Reader) (int64, error) {
-	if action.RemoteGzip {
-		gzw := gzip.NewWriter(w)
-		n, err := io.Copy(gzw, r)
-		if err != nil {
-			return n, err
-		}
-		return n, gzw.Close()
+	cw, err := compression.NewWriter(w, action.wireCompression())
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(cw, r)
+	if err != nil {
+		return n, err
+	}
+	return n, cw.Close()
+}
+
+func (action Restore) wireCompression() compression.Type {
+	switch {
+	case action.Staged && action.Format == sqlformat.Custom:
+		// pg_restore reads the staged file directly
+		return compression.None
+	case action.RemoteZstd && action.remoteZstd:
+		return compression.Zstd
+	case action.RemoteGzip, action.RemoteZstd:
+		return compression.Gzip
+	default:
+		return compression.None
 	}
+}
 
-	n, err := io.Copy(w, r)
-	return n, err
+func (action Restore) hasRemoteZstd(ctx context.Context) bool {
+	err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+		Pod:         action.JobPod,
+		Cmd:         "command -v zstd",
+		Stdout:      io.Discard,
+		DisablePing: true,
+	})
+	return err == nil
 }
 
 func (action Restore) runInDatabasePod(
@@ -264,6 +378,43 @@ func (action Restore) runInDatabasePod(
 	return nil
 }
 
+var ErrNoLatest = errors.New("no dumps found")
+
+// FindLatest replaces Input, which must be a directory or cloud prefix,
+// with the newest dump of the source namespace and database.
+func (action *Restore) FindLatest(ctx context.Context) error {
+	filter := backups.Filter{
+		Namespace: action.SourceNamespace,
+		Database:  action.SourceDatabase,
+	}
+	if filter.Namespace == "" {
+		filter.Namespace = action.Namespace
+	}
+
+	tmpl, err := dump.NewFilenameTemplate(action.FilenameTemplate)
+	if err != nil {
+		return err
+	}
+
+	list, err := backups.List(ctx, action.Input, tmpl)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range list {
+		if filter.Match(b) {
+			slog.Info("Found latest dump", "file", b.Path, "date", b.Date)
+			action.Input = b.Path
+			return nil
+		}
+	}
+
+	if filter.Database != "" {
+		return fmt.Errorf("%w: %s in namespace %s", ErrNoLatest, filter.Database, filter.Namespace)
+	}
+	return fmt.Errorf("%w: namespace %s", ErrNoLatest, filter.Namespace)
+}
+
 func (action Restore) Table(r *lipgloss.Renderer) *tui.Table {
 	return tui.MinimalTable(r).
 		RowIfNotEmpty("Context", action.Context).
@@ -313,6 +464,7 @@ func (action Restore) summary(err error, took time.Duration, written int64, plai
 
 	t := action.Table(r).
 		Row("File", tui.InPath(action.Input, r)).
+		RowIfNotEmpty("Rate Limit", action.LimitRate).
 		Row("Took", took.String())
 	if err != nil {
 		t.Row("Error", tui.ErrStyle(r).Render(err.Error()))
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -17,8 +19,9 @@ import (
 
 func TestRestore_buildCommand(t *testing.T) {
 	type fields struct {
-		Restore conftypes.Restore
-		Analyze bool
+		Restore    conftypes.Restore
+		Analyze    bool
+		remoteZstd bool
 	}
 	type args struct {
 		inputFormat sqlformat.Format
@@ -57,6 +60,62 @@ func TestRestore_buildCommand(t *testing.T) {
 			),
 			require.NoError,
 		},
+		{
+			"postgres-zstd",
+			fields{
+				Restore: conftypes.Restore{
+					Global: &conftypes.Global{
+						Dialect:    postgres.Postgres{},
+						Host:       "1.1.1.1",
+						Database:   "d",
+						Username:   "u",
+						RemoteZstd: true,
+					},
+				},
+				remoteZstd: true,
+			},
+			args{sqlformat.Zstd},
+			command.NewBuilder(
+				"zstd",
+				"--decompress",
+				"--stdout",
+				command.Pipe,
+				command.Raw("{"),
+				"psql",
+				"--host=1.1.1.1",
+				"--username=u",
+				"--dbname=d",
+				command.Raw("|| { cat >/dev/null; kill $$; }; }"),
+			),
+			require.NoError,
+		},
+		{
+			"postgres-zstd-unavailable",
+			fields{
+				Restore: conftypes.Restore{
+					Global: &conftypes.Global{
+						Dialect:    postgres.Postgres{},
+						Host:       "1.1.1.1",
+						Database:   "d",
+						Username:   "u",
+						RemoteZstd: true,
+					},
+				},
+			},
+			args{sqlformat.Zstd},
+			command.NewBuilder(
+				"gunzip",
+				"--force",
+				command.Pipe,
+				command.Raw("{"),
+				"psql",
+				"--host=1.1.1.1",
+				"--username=u",
+				"--dbname=d",
+				command.Raw("|| { cat >/dev/null; kill $$; }; }"),
+			),
+			require.NoError,
+		},
 		{
 			"postgres-plain",
 			fields{
@@ -138,8 +197,9 @@ func TestRestore_buildCommand(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			action := Restore{
-				Restore: tt.fields.Restore,
-				Analyze: tt.fields.Analyze,
+				Restore:    tt.fields.Restore,
+				Analyze:    tt.fields.Analyze,
+				remoteZstd: tt.fields.remoteZstd,
 			}
 			cmd, err := action.buildCommand(tt.args.inputFormat)
 			tt.wantErr(t, err)
@@ -198,3 +258,58 @@ func TestRestore_copy(t *testing.T) {
 		})
 	}
 }
+
+func TestRestore_FindLatest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"prod_2024-03-01_000000.sql.gz",
+		"prod_2024-03-02_000000.sql.gz",
+		"prod_app_2024-03-03_000000.sql.gz",
+		"staging_2024-03-04_000000.sql.gz",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	tests := []struct {
+		name    string
+		restore conftypes.Restore
+		want    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			"current namespace",
+			conftypes.Restore{Global: &conftypes.Global{Kubernetes: conftypes.Kubernetes{Namespace: "staging"}}},
+			"staging_2024-03-04_000000.sql.gz",
+			require.NoError,
+		},
+		{
+			"source namespace",
+			conftypes.Restore{Global: &conftypes.Global{}, SourceNamespace: "prod"},
+			"prod_app_2024-03-03_000000.sql.gz",
+			require.NoError,
+		},
+		{
+			"source database",
+			conftypes.Restore{Global: &conftypes.Global{}, SourceNamespace: "prod", SourceDatabase: "prod"},
+			"prod_2024-03-02_000000.sql.gz",
+			require.NoError,
+		},
+		{
+			"not found",
+			conftypes.Restore{Global: &conftypes.Global{}, SourceNamespace: "dev"},
+			"",
+			require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := &Restore{Restore: tt.restore}
+			action.Input = dir
+			err := action.FindLatest(t.Context())
+			tt.wantErr(t, err)
+			if err == nil {
+				assert.Equal(t, filepath.Join(dir, tt.want), action.Input)
+			}
+		})
+	}
+}
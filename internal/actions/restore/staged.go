@@ -0,0 +1,97 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/progressbar"
+	"github.com/clevyr/kubedb/internal/staging"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/clevyr/kubedb/internal/util"
+)
+
+const stagedFile = util.StageDir + "/restore"
+
+var (
+	ErrStagedJob  = errors.New("staged restores require a job pod")
+	ErrStagedPVC  = errors.New("restores from a pvc cannot be staged")
+	ErrJobsStaged = errors.New("parallel restores require --staged")
+)
+
+// ValidateStaged checks that the dump can be staged in the job pod.
+func (action Restore) ValidateStaged() error {
+	switch {
+	case !action.Staged:
+		if action.Jobs > 1 {
+			return ErrJobsStaged
+		}
+	case !action.CreateJob:
+		return ErrStagedJob
+	case storage.IsPVC(action.Input):
+		return ErrStagedPVC
+	}
+	return nil
+}
+
+// runStaged uploads the dump to a file in the job pod, then restores it from there.
+// Unlike a pipe, the file can be read by parallel pg_restore jobs.
+func (action Restore) runStaged(ctx context.Context, r io.Reader, bar *progressbar.ProgressBar) error {
+	f := staging.New(action.Client, action.JobPod, "", stagedFile, bar.Logger())
+	info, err := f.Upload(ctx, r)
+	if err != nil {
+		return err
+	}
+	_ = bar.Finish()
+
+	slog.Info("Restoring staged dump",
+		"size", bytefmt.Encode(info.Size),
+		"namespace", action.Client.Namespace,
+		"pod", action.DBPod.Name,
+	)
+	restoreBar := progressbar.New(os.Stderr, -1, "restoring", action.Progress, action.Spinner)
+	defer restoreBar.Close()
+
+	staged := action
+	staged.File = stagedFile
+	cmd, err := staged.buildCommand(action.Format)
+	if err != nil {
+		return err
+	}
+	cmd.Unshift(command.Raw("{"))
+	cmd.Push(command.Raw("; } <"), stagedFile)
+
+	if err := action.Client.Exec(ctx, kubernetes.ExecOptions{
+		Pod:         action.JobPod,
+		Cmd:         cmd.String(),
+		Stdout:      restoreBar.Logger(),
+		Stderr:      restoreBar.Logger(),
+		DisablePing: true,
+	}); err != nil {
+		return err
+	}
+
+	if action.Analyze && action.Format == sqlformat.Custom {
+		if db, ok := action.Dialect.(conftypes.DBAnalyzer); ok {
+			pr, pw := io.Pipe()
+			go func() {
+				_, err := action.copy(pw, strings.NewReader(db.AnalyzeQuery()))
+				_ = pw.CloseWithError(err)
+			}()
+			if err := action.runInDatabasePod(ctx, pr, restoreBar.Logger(), restoreBar.Logger(), sqlformat.Gzip); err != nil {
+				return err
+			}
+		}
+	}
+
+	_ = restoreBar.Finish()
+	return nil
+}
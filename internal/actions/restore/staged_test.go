@@ -0,0 +1,66 @@
+package restore
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/postgres"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore_ValidateStaged(t *testing.T) {
+	tests := []struct {
+		name      string
+		staged    bool
+		jobs      int
+		createJob bool
+		input     string
+		wantErr   error
+	}{
+		{"not staged", false, 0, true, "a.dmp", nil},
+		{"jobs without staged", false, 4, true, "a.dmp", ErrJobsStaged},
+		{"staged", true, 4, true, "s3://bucket/a.dmp", nil},
+		{"no job", true, 0, false, "a.dmp", ErrStagedJob},
+		{"pvc", true, 0, true, "pvc://backups/a.dmp", ErrStagedPVC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := Restore{Restore: conftypes.Restore{
+				Global: &conftypes.Global{CreateJob: tt.createJob},
+				Input:  tt.input,
+				Staged: tt.staged,
+				Jobs:   tt.jobs,
+			}}
+			err := action.ValidateStaged()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestore_buildCommand_staged(t *testing.T) {
+	action := Restore{Restore: conftypes.Restore{
+		Global: &conftypes.Global{
+			Dialect:    postgres.Postgres{},
+			Database:   "d",
+			RemoteGzip: true,
+		},
+		Format: sqlformat.Custom,
+		Staged: true,
+		Jobs:   4,
+		File:   stagedFile,
+	}}
+
+	cmd, err := action.buildCommand(sqlformat.Custom)
+	require.NoError(t, err)
+	// The staged file is passed to pg_restore without being compressed for the wire
+	assert.Equal(t,
+		"{ pg_restore --format=custom --dbname=d --verbose --jobs=4 /kubedb-stage/restore || { cat >/dev/null; kill $$; }; }",
+		cmd.String(),
+	)
+}
@@ -0,0 +1,243 @@
+package backups
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/encryption"
+	"github.com/clevyr/kubedb/internal/manifest"
+	"github.com/clevyr/kubedb/internal/split"
+	"github.com/clevyr/kubedb/internal/storage"
+)
+
+// Backup is a dump which was found in a local directory or cloud prefix.
+type Backup struct {
+	dump.Filename
+
+	Path         string
+	Size         int64
+	LastModified time.Time
+	HasManifest  bool
+	// Parts contains the volume paths if the dump was split
+	Parts []string
+}
+
+// Format guesses the dump format from the file extension.
+func (b *Backup) Format() sqlformat.Format {
+	for _, db := range database.All() {
+		if db, ok := db.(conftypes.DBFiler); ok {
+			if format := database.DetectFormat(db, b.Ext); format != sqlformat.Unknown {
+				return format
+			}
+		}
+	}
+	return sqlformat.Unknown
+}
+
+func (b *Backup) IsEncrypted() bool {
+	return strings.HasSuffix(b.Ext, encryption.Ext)
+}
+
+// List finds all files in dir whose names were generated by tmpl.
+// Results are sorted newest first.
+func List(ctx context.Context, dir string, tmpl *dump.FilenameTemplate) ([]*Backup, error) {
+	var result []*Backup
+	var err error
+	if storage.IsCloud(dir) {
+		result, err = listCloud(ctx, dir, tmpl)
+	} else {
+		result, err = listLocal(dir, tmpl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge split volumes into a single backup
+	volumes := make(map[string]*Backup)
+	result = slices.DeleteFunc(result, func(backup *Backup) bool {
+		base, _, ok := split.Trim(backup.Path)
+		if !ok {
+			return false
+		}
+		if owner, ok := volumes[base]; ok {
+			owner.Parts = append(owner.Parts, backup.Path)
+			owner.Size += backup.Size
+			if backup.LastModified.After(owner.LastModified) {
+				owner.LastModified = backup.LastModified
+			}
+			return true
+		}
+		backup.Parts = []string{backup.Path}
+		backup.Path = base
+		backup.Ext, _, _ = split.Trim(backup.Ext)
+		volumes[base] = backup
+		return false
+	})
+	for _, backup := range volumes {
+		slices.Sort(backup.Parts)
+	}
+
+	// Attach manifests to their dumps
+	paths := make(map[string]*Backup, len(result))
+	for _, backup := range result {
+		paths[backup.Path] = backup
+	}
+	result = slices.DeleteFunc(result, func(backup *Backup) bool {
+		if owner, ok := paths[strings.TrimSuffix(backup.Path, manifest.Ext)]; ok && owner != backup {
+			owner.HasManifest = true
+			return true
+		}
+		return false
+	})
+
+	slices.SortStableFunc(result, func(a, b *Backup) int {
+		return b.Date.Compare(a.Date)
+	})
+	return result, nil
+}
+
+func listLocal(dir string, tmpl *dump.FilenameTemplate) ([]*Backup, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	var result []*Backup
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			// Only descend as far as the template can generate directories
+			if rel != "." && strings.Count(filepath.ToSlash(rel), "/") >= tmpl.Depth() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		filename, err := tmpl.Parse(filepath.ToSlash(rel))
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		result = append(result, &Backup{
+			Filename:     filename,
+			Path:         path,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func listCloud(ctx context.Context, dir string, tmpl *dump.FilenameTemplate) ([]*Backup, error) {
+	u, err := url.Parse(dir)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path != "" && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	client, err := storage.NewClient(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return listCloudPrefix(ctx, client, *u, tmpl, tmpl.Depth())
+}
+
+func listCloudPrefix(
+	ctx context.Context,
+	client storage.Client,
+	u url.URL,
+	tmpl *dump.FilenameTemplate,
+	depth int,
+) ([]*Backup, error) {
+	var result []*Backup
+	var dirs []string
+	for object, err := range client.ListObjects(ctx, u.String()) {
+		if err != nil {
+			return nil, err
+		}
+		if object.IsDir {
+			dirs = append(dirs, object.Name)
+			continue
+		}
+
+		filename, err := tmpl.Parse(object.Name)
+		if err != nil {
+			continue
+		}
+
+		u := u
+		u.Path = object.Name
+		result = append(result, &Backup{
+			Filename:     filename,
+			Path:         u.String(),
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+
+	if depth > 0 {
+		// Descend into directories generated by the template
+		for _, dir := range dirs {
+			u.Path = dir
+			if !strings.HasSuffix(u.Path, "/") {
+				u.Path += "/"
+			}
+			list, err := listCloudPrefix(ctx, client, u, tmpl, depth-1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, list...)
+		}
+	}
+	return result, nil
+}
+
+// Group identifies the namespace and database a backup was taken from.
+type Group struct {
+	Namespace string
+	Database  string
+}
+
+func (b *Backup) Group() Group {
+	database := b.Database
+	if database == "" {
+		database = b.Namespace
+	}
+	return Group{Namespace: b.Namespace, Database: database}
+}
+
+func CompareGroup(a, b Group) int {
+	return cmp.Or(
+		cmp.Compare(a.Namespace, b.Namespace),
+		cmp.Compare(a.Database, b.Database),
+	)
+}
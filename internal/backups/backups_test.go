@@ -0,0 +1,97 @@
+package backups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"test_2024-03-01_000000.sql.gz",
+		"test_2024-03-02_000000.sql.gz",
+		"test_2024-03-02_000000.sql.gz.json",
+		"test_app_2024-03-03_000000.dump",
+		"notes.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "test_2024-03-04_000000.sql"), 0o755))
+
+	tmpl, err := dump.NewFilenameTemplate("")
+	require.NoError(t, err)
+
+	got, err := List(t.Context(), dir, tmpl)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, filepath.Join(dir, "test_app_2024-03-03_000000.dump"), got[0].Path)
+	assert.Equal(t, Group{Namespace: "test", Database: "app"}, got[0].Group())
+	assert.False(t, got[0].HasManifest)
+
+	assert.Equal(t, filepath.Join(dir, "test_2024-03-02_000000.sql.gz"), got[1].Path)
+	assert.Equal(t, Group{Namespace: "test", Database: "test"}, got[1].Group())
+	assert.True(t, got[1].HasManifest)
+
+	assert.Equal(t, filepath.Join(dir, "test_2024-03-01_000000.sql.gz"), got[2].Path)
+}
+
+func TestList_Folders(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"2024/03/test_2024-03-01_000000.sql.gz",
+		"2024/04/test_2024-04-01_000000.sql.gz",
+		"2024/04/nested/test_2024-04-02_000000.sql.gz",
+		"test_2024-05-01_000000.sql.gz",
+	} {
+		name = filepath.FromSlash(name)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	tmpl, err := dump.NewFilenameTemplate(
+		`{{ .Date.Format "2006/01" }}/{{ .Namespace }}_{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`,
+	)
+	require.NoError(t, err)
+
+	got, err := List(t.Context(), dir, tmpl)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, filepath.Join(dir, "2024", "04", "test_2024-04-01_000000.sql.gz"), got[0].Path)
+	assert.Equal(t, filepath.Join(dir, "2024", "03", "test_2024-03-01_000000.sql.gz"), got[1].Path)
+}
+
+func TestList_Split(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{
+		"test_2024-03-01_000000.sql.gz.part001":     4,
+		"test_2024-03-01_000000.sql.gz.part002":     2,
+		"test_2024-03-01_000000.sql.gz.json":        0,
+		"test_2024-03-02_000000.sql.gz":             1,
+		"test_app_2024-03-02_000000.sql.gz.part001": 3,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o600))
+	}
+
+	tmpl, err := dump.NewFilenameTemplate("")
+	require.NoError(t, err)
+
+	got, err := List(t.Context(), dir, tmpl)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	split := got[2]
+	assert.Equal(t, filepath.Join(dir, "test_2024-03-01_000000.sql.gz"), split.Path)
+	assert.Equal(t, ".sql.gz", split.Ext)
+	assert.Equal(t, int64(6), split.Size)
+	assert.True(t, split.HasManifest)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "test_2024-03-01_000000.sql.gz.part001"),
+		filepath.Join(dir, "test_2024-03-01_000000.sql.gz.part002"),
+	}, split.Parts)
+}
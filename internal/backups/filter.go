@@ -0,0 +1,49 @@
+package backups
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+)
+
+// Filter matches backups by namespace, database, and date range. Zero values match everything.
+type Filter struct {
+	Namespace string
+	Database  string
+	Since     time.Time
+	Until     time.Time
+}
+
+func (f Filter) Match(b *Backup) bool {
+	group := b.Group()
+	switch {
+	case f.Namespace != "" && group.Namespace != f.Namespace,
+		f.Database != "" && group.Database != f.Database,
+		!f.Since.IsZero() && b.Date.Before(f.Since),
+		!f.Until.IsZero() && b.Date.After(f.Until):
+		return false
+	}
+	return true
+}
+
+var ErrInvalidTime = errors.New("invalid time")
+
+// ParseTime parses a date, a timestamp, or a duration relative to now.
+func ParseTime(now time.Time, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly, dump.DateFormat} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, s)
+}
@@ -0,0 +1,63 @@
+package backups
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Match(t *testing.T) {
+	backup := &Backup{Filename: dump.Filename{
+		Namespace: "test",
+		Database:  "app",
+		Date:      time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local),
+	}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"namespace", Filter{Namespace: "test"}, true},
+		{"other namespace", Filter{Namespace: "other"}, false},
+		{"database", Filter{Database: "app"}, true},
+		{"other database", Filter{Database: "other"}, false},
+		{"since", Filter{Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)}, true},
+		{"before since", Filter{Since: time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)}, false},
+		{"until", Filter{Until: time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)}, true},
+		{"after until", Filter{Until: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(backup))
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		s       string
+		want    time.Time
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"empty", "", time.Time{}, require.NoError},
+		{"duration", "24h", now.Add(-24 * time.Hour), require.NoError},
+		{"date", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), require.NoError},
+		{"datetime", "2024-03-01 13:04:05", time.Date(2024, 3, 1, 13, 4, 5, 0, time.Local), require.NoError},
+		{"dump format", "2024-03-01_130405", time.Date(2024, 3, 1, 13, 4, 5, 0, time.Local), require.NoError},
+		{"invalid", "yesterday", time.Time{}, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(now, tt.s)
+			tt.wantErr(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Type is a stream compression algorithm.
+type Type uint8
+
+const (
+	None Type = iota
+	Gzip
+	Zstd
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// FromFormat returns the compression used by a file format.
+func FromFormat(format sqlformat.Format) Type {
+	switch format {
+	case sqlformat.Gzip:
+		return Gzip
+	case sqlformat.Zstd:
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// Detect checks the magic bytes at the start of r without consuming them.
+func Detect(r *bufio.Reader) Type {
+	b, _ := r.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(b, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(b, zstdMagic):
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// NewReader returns a ReadCloser which decompresses r.
+func NewReader(r io.Reader, t Type) (io.ReadCloser, error) {
+	switch t {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// NewWriter returns a WriteCloser which compresses to w. Close must be called to flush the stream.
+func NewWriter(w io.Writer, t Type) (io.WriteCloser, error) {
+	switch t {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// Convert returns a ReadCloser which recompresses r from one Type to another.
+func Convert(r io.Reader, from, to Type) (io.ReadCloser, error) {
+	if from == to {
+		return io.NopCloser(r), nil
+	}
+
+	decompressed, err := NewReader(r, from)
+	if err != nil {
+		return nil, err
+	}
+	if to == None {
+		return decompressed, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() {
+			_ = decompressed.Close()
+		}()
+
+		w, err := NewWriter(pw, to)
+		if err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(w, decompressed); err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+		_ = pw.CloseWithError(w.Close())
+	}()
+	return pr, nil
+}
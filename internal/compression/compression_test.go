@@ -0,0 +1,60 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, input string, typ Type) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, typ)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, input)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestFromFormat(t *testing.T) {
+	assert.Equal(t, Gzip, FromFormat(sqlformat.Gzip))
+	assert.Equal(t, Zstd, FromFormat(sqlformat.Zstd))
+	assert.Equal(t, None, FromFormat(sqlformat.Plain))
+	assert.Equal(t, None, FromFormat(sqlformat.Custom))
+}
+
+func TestDetect(t *testing.T) {
+	for _, typ := range []Type{None, Gzip, Zstd} {
+		r := bufio.NewReader(bytes.NewReader(compress(t, "SELECT 1;", typ)))
+		assert.Equal(t, typ, Detect(r))
+	}
+	assert.Equal(t, None, Detect(bufio.NewReader(strings.NewReader(""))))
+}
+
+func TestConvert(t *testing.T) {
+	const input = "SELECT 1;"
+	types := []Type{None, Gzip, Zstd}
+	for _, from := range types {
+		for _, to := range types {
+			r, err := Convert(bytes.NewReader(compress(t, input, from)), from, to)
+			require.NoError(t, err)
+			converted, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+
+			assert.Equal(t, to, Detect(bufio.NewReader(bytes.NewReader(converted))))
+
+			decompressed, err := NewReader(bytes.NewReader(converted), to)
+			require.NoError(t, err)
+			got, err := io.ReadAll(decompressed)
+			require.NoError(t, err)
+			assert.Equal(t, input, string(got))
+		}
+	}
+}
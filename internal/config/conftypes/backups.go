@@ -0,0 +1,9 @@
+package conftypes
+
+type Backups struct {
+	*Global `koanf:"-"`
+	Dir     string `koanf:"-"`
+	Output  string `koanf:"output"`
+	Since   string `koanf:"since"`
+	Until   string `koanf:"until"`
+}
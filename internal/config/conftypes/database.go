@@ -86,3 +86,8 @@ type DBAnalyzer interface {
 type DBCanDisableJob interface {
 	DisableJob() bool
 }
+
+// DBJobVolumer mounts volumes from the database container in the job container.
+type DBJobVolumer interface {
+	JobVolumes(conf *Global, container corev1.Container) ([]corev1.Volume, []corev1.VolumeMount, error)
+}
@@ -4,7 +4,7 @@ import "github.com/clevyr/kubedb/internal/database/sqlformat"
 
 type Dump struct {
 	*Global          `koanf:"-"`
-	Output           string           `koanf:"output"`
+	Output           []string         `koanf:"output"`
 	Format           sqlformat.Format `koanf:"format"`
 	IfExists         bool             `koanf:"if-exists"`
 	Clean            bool             `koanf:"clean"`
@@ -12,4 +12,19 @@ type Dump struct {
 	Table            []string         `koanf:"table"`
 	ExcludeTable     []string         `koanf:"exclude-table"`
 	ExcludeTableData []string         `koanf:"exclude-table-data"`
+	Recipients       []string         `koanf:"recipients"`
+	Passphrase       string           `koanf:"passphrase"`
+	SplitSize        string           `koanf:"split-size"`
+	Detach           bool             `koanf:"detach"`
+	Staged           bool             `koanf:"staged"`
+	Upload           `koanf:",squash"`
+}
+
+// Upload configures objects which are written to cloud storage.
+type Upload struct {
+	SSE          string            `koanf:"sse"`
+	SSEKMSKeyID  string            `koanf:"sse-kms-key-id"`
+	StorageClass string            `koanf:"storage-class"`
+	Tags         map[string]string `koanf:"tags"`
+	Metadata     map[string]string `koanf:"metadata"`
 }
@@ -27,6 +27,13 @@ type Global struct {
 	JobPod              corev1.Pod        `koanf:"-"`
 	JobPodLabels        map[string]string `koanf:"job-pod-labels"`
 	DBPod               corev1.Pod        `koanf:"-"`
+	ClusterUpload       ClusterUpload     `koanf:",squash"`
+	// Claims are mounted in the job pod under util.PVCDir
+	Claims []string `koanf:"-"`
+	// PVCAppPod accesses pvc:// paths through a running pod which mounts the claim
+	PVCAppPod bool `koanf:"pvc-app-pod"`
+	// Stage mounts an emptyDir in the job pod under util.StageDir
+	Stage bool `koanf:"-"`
 
 	Host       string `koanf:"-"`
 	Port       uint16 `koanf:"port"`
@@ -35,11 +42,28 @@ type Global struct {
 	Password   string `koanf:"password"`
 	Quiet      bool   `koanf:"quiet"`
 	RemoteGzip bool   `koanf:"remote-gzip"`
+	RemoteZstd bool   `koanf:"remote-zstd"`
+	LimitRate  string `koanf:"limit-rate"`
 	Opts       string `koanf:"opts"`
 	Spinner    string `koanf:"spinner"`
 
 	Progress            bool   `koanf:"progress"`
 	HealthchecksPingURL string `koanf:"healthchecks-ping-url"`
 
-	NamespaceColors map[string]string `koanf:"namespace-colors"`
+	NamespaceColors     map[string]string `koanf:"namespace-colors"`
+	NamespaceLimitRates map[string]string `koanf:"namespace-limit-rates"`
+
+	FilenameTemplate string `koanf:"filename-template"`
+
+	Storage Storage `koanf:"storage"`
+}
+
+// ClusterUpload configures uploads which run in the job pod instead of on the local machine.
+type ClusterUpload struct {
+	Enabled        bool   `koanf:"upload-from-cluster"`
+	Image          string `koanf:"upload-image"`
+	Secret         string `koanf:"upload-secret"`
+	ServiceAccount string `koanf:"upload-service-account"`
+	// Ports are the TCP ports the uploader connects to, derived from the outputs.
+	Ports []int32 `koanf:"-"`
 }
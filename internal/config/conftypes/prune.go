@@ -0,0 +1,12 @@
+package conftypes
+
+type Prune struct {
+	*Global     `koanf:"-"`
+	Dir         string `koanf:"-"`
+	KeepLast    int    `koanf:"keep-last"`
+	KeepDaily   int    `koanf:"keep-daily"`
+	KeepWeekly  int    `koanf:"keep-weekly"`
+	KeepMonthly int    `koanf:"keep-monthly"`
+	DryRun      bool   `koanf:"dry-run"`
+	Force       bool   `koanf:"force"`
+}
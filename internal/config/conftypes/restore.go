@@ -12,4 +12,13 @@ type Restore struct {
 	Force             bool             `koanf:"force"`
 	Spinner           string           `koanf:"spinner"`
 	HaltOnError       bool             `koanf:"halt-on-error"`
+	Identities        []string         `koanf:"identities"`
+	Passphrase        string           `koanf:"passphrase"`
+	Latest            bool             `koanf:"latest"`
+	SourceNamespace   string           `koanf:"source-namespace"`
+	SourceDatabase    string           `koanf:"source-dbname"`
+	Staged            bool             `koanf:"staged"`
+	Jobs              int              `koanf:"jobs"`
+	// File is read by the restore command instead of stdin when the dump is staged in the job pod
+	File string `koanf:"-"`
 }
@@ -0,0 +1,40 @@
+package conftypes
+
+type Storage struct {
+	HTTP    []HTTPSource      `koanf:"http"`
+	Remotes map[string]Remote `koanf:"remotes"`
+}
+
+type HTTPSource struct {
+	Host    string            `koanf:"host"`
+	Headers map[string]string `koanf:"headers"`
+}
+
+// Remote is a named storage location which can be referenced as remote://<name>/<path>.
+type Remote struct {
+	URL          string            `koanf:"url"`
+	Endpoint     string            `koanf:"endpoint"`
+	Region       string            `koanf:"region"`
+	Prefix       string            `koanf:"prefix"`
+	StorageClass string            `koanf:"storage-class"`
+	Credentials  RemoteCredentials `koanf:"credentials"`
+}
+
+type CredentialsSource string
+
+const (
+	CredentialsDefault CredentialsSource = ""
+	CredentialsEnv     CredentialsSource = "env"
+	CredentialsProfile CredentialsSource = "profile"
+	CredentialsFile    CredentialsSource = "file"
+	CredentialsStatic  CredentialsSource = "static"
+)
+
+type RemoteCredentials struct {
+	Source          CredentialsSource `koanf:"source"`
+	Profile         string            `koanf:"profile"`
+	File            string            `koanf:"file"`
+	AccessKeyID     string            `koanf:"access-key-id"`
+	SecretAccessKey string            `koanf:"secret-access-key"`
+	SessionToken    string            `koanf:"session-token"`
+}
@@ -24,11 +24,12 @@ func Dialect(cmd *cobra.Command) {
 
 func Format(cmd *cobra.Command) {
 	format := sqlformat.Gzip
-	cmd.Flags().VarP(&format, consts.FlagFormat, "F", `Output file format (one of gzip, custom, plain)`)
+	cmd.Flags().VarP(&format, consts.FlagFormat, "F", `Output file format (one of gzip, zstd, custom, plain)`)
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagFormat,
 		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 			return []string{
 				sqlformat.Gzip.String(),
+				sqlformat.Zstd.String(),
 				sqlformat.Plain.String(),
 				sqlformat.Custom.String(),
 			}, cobra.ShellCompDirectiveNoFileComp
@@ -100,6 +101,21 @@ func HaltOnError(cmd *cobra.Command) {
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagHaltOnError, completion.BoolCompletion))
 }
 
+func Recipients(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(consts.FlagRecipients, nil, "Encrypt the dump to age or ssh public keys, or files containing them")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagRecipients, cobra.FixedCompletions(nil, cobra.ShellCompDirectiveDefault)))
+}
+
+func Identities(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(consts.FlagIdentities, nil, "Decrypt the file using age secret keys, or age or ssh identity files")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagIdentities, cobra.FixedCompletions(nil, cobra.ShellCompDirectiveDefault)))
+}
+
+func Passphrase(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagPassphrase, "", "Encryption passphrase (can also be set with KUBEDB_PASSPHRASE)")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagPassphrase, cobra.NoFileCompletions))
+}
+
 func Opts(cmd *cobra.Command) {
 	cmd.Flags().String(consts.FlagOpts, "", "Additional options to pass to the database client command")
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagOpts, cobra.NoFileCompletions))
@@ -21,6 +21,14 @@ func Config(cmd *cobra.Command) {
 	cmd.PersistentFlags().String(consts.FlagConfig, confPath, "Path to the config file")
 }
 
+func FilenameTemplate(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagFilenameTemplate, consts.DefaultFilenameTemplate,
+		"Go text/template used to generate and parse dump filenames. "+
+			"Available fields: .Context, .Namespace, .Pod, .Dialect, .Database, .Username, .Date, .Ext",
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagFilenameTemplate, cobra.NoFileCompletions))
+}
+
 func Spinner(cmd *cobra.Command) {
 	cmd.Flags().String(consts.FlagSpinner, consts.DefaultSpinner,
 		"Spinner from https://jsfiddle.net/sindresorhus/2eLtsbey/embedded/result/",
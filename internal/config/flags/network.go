@@ -13,3 +13,19 @@ func RemoteGzip(cmd *cobra.Command) {
 	)
 	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagRemoteGzip, completion.BoolCompletion))
 }
+
+func LimitRate(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagLimitRate, "",
+		`Limit the transfer rate, for example "20MiB/s". Overrides namespace-limit-rates from the config file.`,
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagLimitRate, func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"1MiB/s", "10MiB/s", "100MiB/s"}, cobra.ShellCompDirectiveNoFileComp
+	}))
+}
+
+func RemoteZstd(cmd *cobra.Command) {
+	cmd.Flags().Bool(consts.FlagRemoteZstd, false,
+		"Compress data over the wire with zstd instead of gzip. Falls back to gzip if zstd is not installed in the pod.",
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagRemoteZstd, completion.BoolCompletion))
+}
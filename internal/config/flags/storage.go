@@ -0,0 +1,131 @@
+package flags
+
+import (
+	"context"
+	"log/slog"
+
+	"gabe565.com/utils/must"
+	"github.com/clevyr/kubedb/internal/completion"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/consts"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func SSE(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagSSE, "", `Server-side encryption for uploads ("AES256" or "aws:kms")`)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSSE,
+		cobra.FixedCompletions([]string{"AES256", "aws:kms"}, cobra.ShellCompDirectiveNoFileComp),
+	))
+}
+
+func SSEKMSKeyID(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagSSEKMSKeyID, "",
+		"KMS key used to encrypt uploads. For GCS, this is a CMEK key name. For Azure, this is an encryption scope.",
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagSSEKMSKeyID, cobra.NoFileCompletions))
+}
+
+func StorageClass(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagStorageClass, "", `Storage class for uploads, for example "GLACIER_IR" (overrides the remote's storage class)`)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagStorageClass,
+		cobra.FixedCompletions([]string{
+			"STANDARD", "STANDARD_IA", "INTELLIGENT_TIERING", "GLACIER_IR", "GLACIER", "DEEP_ARCHIVE",
+			"NEARLINE", "COLDLINE", "ARCHIVE",
+		}, cobra.ShellCompDirectiveNoFileComp),
+	))
+}
+
+func Tags(cmd *cobra.Command) {
+	cmd.Flags().StringToString(consts.FlagTags, map[string]string{}, "Object tags to add to uploads (S3 and Azure)")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagTags, cobra.NoFileCompletions))
+}
+
+func Metadata(cmd *cobra.Command) {
+	cmd.Flags().StringToString(consts.FlagMetadata, map[string]string{}, "User metadata to add to uploads")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagMetadata, cobra.NoFileCompletions))
+}
+
+func UploadFromCluster(cmd *cobra.Command) {
+	cmd.Flags().Bool(consts.FlagUploadFromCluster, false,
+		"Upload directly from the job pod instead of streaming the dump through this machine",
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagUploadFromCluster, completion.BoolCompletion))
+}
+
+func UploadImage(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagUploadImage, "", "Image used to upload from the job pod (default ghcr.io/clevyr/kubedb)")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagUploadImage, cobra.NoFileCompletions))
+}
+
+func PVCAppPod(cmd *cobra.Command) {
+	cmd.Flags().Bool(consts.FlagPVCAppPod, false,
+		"Access pvc:// paths through a running pod which mounts the claim instead of starting a kubedb pod",
+	)
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagPVCAppPod, completion.BoolCompletion))
+}
+
+func UploadSecret(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagUploadSecret, "", "Secret containing storage credentials which are loaded into the upload container's environment")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagUploadSecret,
+		func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completeNames(cmd, func(ctx context.Context, client kubernetes.KubeClient) ([]string, error) {
+				list, err := client.Secrets().List(ctx, metav1.ListOptions{})
+				if err != nil {
+					return nil, err
+				}
+				names := make([]string, 0, len(list.Items))
+				for _, secret := range list.Items {
+					names = append(names, secret.Name)
+				}
+				return names, nil
+			})
+		}),
+	)
+}
+
+func UploadServiceAccount(cmd *cobra.Command) {
+	cmd.Flags().String(consts.FlagUploadServiceAccount, "", "Service account for the job pod, for example to use workload identity")
+	must.Must(cmd.RegisterFlagCompletionFunc(consts.FlagUploadServiceAccount,
+		func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completeNames(cmd, func(ctx context.Context, client kubernetes.KubeClient) ([]string, error) {
+				list, err := client.ServiceAccounts().List(ctx, metav1.ListOptions{})
+				if err != nil {
+					return nil, err
+				}
+				names := make([]string, 0, len(list.Items))
+				for _, account := range list.Items {
+					names = append(names, account.Name)
+				}
+				return names, nil
+			})
+		}),
+	)
+}
+
+func completeNames(
+	cmd *cobra.Command,
+	list func(ctx context.Context, client kubernetes.KubeClient) ([]string, error),
+) ([]string, cobra.ShellCompDirective) {
+	if err := completion.LoadConfig(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	client, err := kubernetes.NewClient(
+		config.Global.Kubeconfig,
+		config.Global.Context,
+		config.Global.Namespace,
+	)
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names, err := list(cmd.Context(), client)
+	if err != nil {
+		slog.Error("Failed to list resources", "error", err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,3 +1,6 @@
 package consts
 
 const DefaultSpinner = "dots14"
+
+const DefaultFilenameTemplate = `{{ .Namespace }}_{{ if and .Database (ne .Database .Namespace) }}{{ .Database }}_{{ end }}` +
+	`{{ .Date.Format "2006-01-02_150405" }}{{ .Ext }}`
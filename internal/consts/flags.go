@@ -18,6 +18,9 @@ const (
 	FlagAnalyze           = "analyze"
 	FlagHaltOnError       = "halt-on-error"
 	FlagOpts              = "opts"
+	FlagRecipients        = "recipients"
+	FlagIdentities        = "identities"
+	FlagPassphrase        = "passphrase"
 
 	FlagSpinner = "spinner"
 
@@ -37,6 +40,8 @@ const (
 	FlagHealthchecksPingURL = "healthchecks-ping-url"
 
 	FlagRemoteGzip = "remote-gzip"
+	FlagRemoteZstd = "remote-zstd"
+	FlagLimitRate  = "limit-rate"
 
 	FlagListenPort = "listen-port"
 	FlagAddress    = "address"
@@ -45,5 +50,36 @@ const (
 	FlagOutput     = "output"
 	FlagForce      = "force"
 
+	FlagFilenameTemplate = "filename-template"
+	FlagSplitSize        = "split-size"
+
+	FlagSSE          = "sse"
+	FlagSSEKMSKeyID  = "sse-kms-key-id"
+	FlagStorageClass = "storage-class"
+	FlagTags         = "tags"
+	FlagMetadata     = "metadata"
+
+	FlagUploadFromCluster    = "upload-from-cluster"
+	FlagUploadImage          = "upload-image"
+	FlagUploadSecret         = "upload-secret"
+	FlagUploadServiceAccount = "upload-service-account"
+	FlagPVCAppPod            = "pvc-app-pod"
+	FlagDetach               = "detach"
+	FlagStaged               = "staged"
+	FlagJobs                 = "jobs"
+
+	FlagKeepLast    = "keep-last"
+	FlagKeepDaily   = "keep-daily"
+	FlagKeepWeekly  = "keep-weekly"
+	FlagKeepMonthly = "keep-monthly"
+	FlagDryRun      = "dry-run"
+
+	FlagSince = "since"
+	FlagUntil = "until"
+
+	FlagLatest          = "latest"
+	FlagSourceNamespace = "source-namespace"
+	FlagSourceDBName    = "source-dbname"
+
 	KeyNamespaceColor = "ui.colors.namespace"
 )
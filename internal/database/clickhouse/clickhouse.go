@@ -0,0 +1,197 @@
+package clickhouse
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/kubernetes/filter"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+var (
+	_ conftypes.DBAliaser         = ClickHouse{}
+	_ conftypes.DBOrderer         = ClickHouse{}
+	_ conftypes.DBDumper          = ClickHouse{}
+	_ conftypes.DBExecer          = ClickHouse{}
+	_ conftypes.DBRestorer        = ClickHouse{}
+	_ conftypes.DBHasUser         = ClickHouse{}
+	_ conftypes.DBHasPort         = ClickHouse{}
+	_ conftypes.DBHasPassword     = ClickHouse{}
+	_ conftypes.DBHasDatabase     = ClickHouse{}
+	_ conftypes.DBDatabaseLister  = ClickHouse{}
+	_ conftypes.DBDatabaseDropper = ClickHouse{}
+	_ conftypes.DBTableLister     = ClickHouse{}
+)
+
+type ClickHouse struct{}
+
+func (ClickHouse) Name() string { return "clickhouse" }
+
+func (ClickHouse) PrettyName() string { return "ClickHouse" }
+
+func (ClickHouse) Aliases() []string { return []string{"ch"} }
+
+func (ClickHouse) Priority() uint8 { return 255 }
+
+func (ClickHouse) PortEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"CLICKHOUSE_TCP_PORT"}}
+}
+
+func (ClickHouse) PortDefault() uint16 { return 9000 }
+
+func (ClickHouse) DatabaseEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"CLICKHOUSE_DB"}}
+}
+
+func (ClickHouse) DatabaseListQuery() string { return "SHOW DATABASES" }
+
+func (ClickHouse) TableListQuery() string { return "SHOW TABLES" }
+
+func (ClickHouse) UserEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"CLICKHOUSE_ADMIN_USER", "CLICKHOUSE_USER"}}
+}
+
+func (ClickHouse) UserDefault() string { return "default" }
+
+func (ClickHouse) PasswordEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"CLICKHOUSE_ADMIN_PASSWORD", "CLICKHOUSE_PASSWORD"}}
+}
+
+func (db ClickHouse) DatabaseDropQuery(database string) string {
+	database = db.quoteIdentifier(database)
+	return "DROP DATABASE IF EXISTS " + database + "; CREATE DATABASE " + database + "; USE " + database + ";"
+}
+
+func (ClickHouse) PodFilters() filter.Filter {
+	return filter.Or{
+		filter.And{
+			filter.Label{Name: "app.kubernetes.io/name", Value: "clickhouse"},
+			filter.Label{
+				Name:     "app.kubernetes.io/component",
+				Operator: selection.NotEquals,
+				Value:    "keeper",
+			},
+		},
+		// Altinity operator
+		filter.Label{Name: "clickhouse.altinity.com/app", Value: "chop"},
+		filter.Label{Name: "app", Value: "clickhouse"},
+	}
+}
+
+func (ClickHouse) newCmd(conf *conftypes.Global, p ...any) *command.Builder {
+	cmd := command.NewBuilder(p...)
+	if conf.Host != "" {
+		cmd.Push("--host=" + conf.Host)
+	}
+	if conf.Port != 0 {
+		cmd.Push("--port=" + strconv.Itoa(int(conf.Port)))
+	}
+	if conf.Username != "" {
+		cmd.Push("--user=" + conf.Username)
+	}
+	if conf.Password != "" {
+		cmd.Unshift(command.NewEnv("CLICKHOUSE_PASSWORD", conf.Password))
+	}
+	if conf.Database != "" {
+		cmd.Push("--database=" + conf.Database)
+	}
+	return cmd
+}
+
+func (db ClickHouse) ExecCommand(conf *conftypes.Exec) *command.Builder {
+	cmd := db.newCmd(conf.Global, "exec", "clickhouse-client")
+	if conf.DisableHeaders {
+		cmd.Push("--format=TSVRaw")
+	}
+	if conf.Command != "" {
+		cmd.Push("--query=" + conf.Command)
+	}
+	return cmd
+}
+
+//go:embed dump.sh
+var dumpScript string
+
+// DumpCommand writes the schema and data of each table as SQL statements.
+func (db ClickHouse) DumpCommand(conf *conftypes.Dump) *command.Builder {
+	client := db.newCmd(conf.Global, "clickhouse-client")
+	cmd := command.NewBuilder(
+		command.NewEnv("TABLES_QUERY", db.tablesQuery(conf)),
+		"sh", "-c", "client() { "+client.String()+` "$@"; }`+"\n"+dumpScript,
+	)
+	if conf.Clean {
+		cmd.Unshift(command.NewEnv("CLEAN", "true"))
+	}
+	return cmd
+}
+
+// noDataEngines contain no data of their own, so only their schema is dumped.
+var noDataEngines = []string{ //nolint:gochecknoglobals
+	"View", "MaterializedView", "LiveView", "WindowView", "Dictionary",
+	"Distributed", "Merge", "Buffer", "Null",
+	"Kafka", "RabbitMQ", "NATS",
+}
+
+// tablesQuery lists the tables to dump. Views are listed last since they depend on tables.
+func (db ClickHouse) tablesQuery(conf *conftypes.Dump) string {
+	engines := make([]string, 0, len(noDataEngines))
+	for _, engine := range noDataEngines {
+		engines = append(engines, db.quoteString(engine))
+	}
+
+	var buf strings.Builder
+	buf.WriteString("SELECT concat('`', replaceAll(name, '`', '\\\\`'), '`'), ")
+	buf.WriteString("engine NOT IN (" + strings.Join(engines, ", ") + "), ")
+	// Remove the database from the statement so it can be restored to another database
+	buf.WriteString("replaceOne(create_table_query, concat(' ', database, '.'), ' ') ")
+	buf.WriteString("FROM system.tables WHERE database = currentDatabase() AND NOT is_temporary AND NOT startsWith(name, '.inner')")
+	if len(conf.Table) != 0 {
+		buf.WriteString(" AND name IN (" + db.quoteStrings(conf.Table) + ")")
+	}
+	if len(conf.ExcludeTable) != 0 {
+		buf.WriteString(" AND name NOT IN (" + db.quoteStrings(conf.ExcludeTable) + ")")
+	}
+	buf.WriteString(" ORDER BY engine LIKE '%View', name")
+	return buf.String()
+}
+
+func (db ClickHouse) RestoreCommand(conf *conftypes.Restore, _ sqlformat.Format) *command.Builder {
+	cmd := db.newCmd(conf.Global, "clickhouse-client", "--multiquery")
+	if !conf.HaltOnError {
+		cmd.Push("--ignore-error")
+	}
+	return cmd
+}
+
+func (ClickHouse) Formats() map[sqlformat.Format]string {
+	return map[sqlformat.Format]string{
+		sqlformat.Plain: ".sql",
+		sqlformat.Gzip:  ".sql.gz",
+		sqlformat.Zstd:  ".sql.zst",
+	}
+}
+
+func (ClickHouse) quoteIdentifier(param string) string {
+	param = strings.ReplaceAll(param, `\`, `\\`)
+	param = strings.ReplaceAll(param, "`", "\\`")
+	return "`" + param + "`"
+}
+
+func (ClickHouse) quoteString(param string) string {
+	param = strings.ReplaceAll(param, `\`, `\\`)
+	param = strings.ReplaceAll(param, "'", `\'`)
+	return "'" + param + "'"
+}
+
+func (db ClickHouse) quoteStrings(params []string) string {
+	quoted := make([]string, 0, len(params))
+	for _, param := range params {
+		quoted = append(quoted, db.quoteString(param))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,139 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickHouse_DatabaseDropQuery(t *testing.T) {
+	assert.Equal(t,
+		"DROP DATABASE IF EXISTS `d`; CREATE DATABASE `d`; USE `d`;",
+		ClickHouse{}.DatabaseDropQuery("d"),
+	)
+}
+
+func TestClickHouse_ExecCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Exec
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"}},
+			command.NewBuilder(
+				command.NewEnv("CLICKHOUSE_PASSWORD", "p"),
+				"exec", "clickhouse-client", "--host=1.1.1.1", "--user=u", "--database=d",
+			),
+		},
+		{
+			"port",
+			&conftypes.Exec{Global: &conftypes.Global{Port: 1234}},
+			command.NewBuilder("exec", "clickhouse-client", "--port=1234"),
+		},
+		{
+			"query",
+			&conftypes.Exec{Global: &conftypes.Global{}, DisableHeaders: true, Command: "SHOW TABLES"},
+			command.NewBuilder("exec", "clickhouse-client", "--format=TSVRaw", "--query=SHOW TABLES"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClickHouse{}.ExecCommand(tt.conf))
+		})
+	}
+}
+
+func TestClickHouse_DumpCommand(t *testing.T) {
+	conf := &conftypes.Dump{
+		Global: &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"},
+		Clean:  true,
+	}
+	got := ClickHouse{}.DumpCommand(conf).String()
+	assert.True(t, strings.HasPrefix(got, "CLEAN=true TABLES_QUERY="))
+	assert.Contains(t, got, "client() { CLICKHOUSE_PASSWORD=p clickhouse-client --host=1.1.1.1 --user=u --database=d \"$@\"; }")
+}
+
+func TestClickHouse_tablesQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     *conftypes.Dump
+		contains []string
+		excludes []string
+	}{
+		{
+			"all tables",
+			&conftypes.Dump{},
+			[]string{"FROM system.tables WHERE database = currentDatabase()", "ORDER BY engine LIKE '%View', name"},
+			[]string{"AND name IN", "AND name NOT IN"},
+		},
+		{
+			"filtered",
+			&conftypes.Dump{Table: []string{"events", "it's"}, ExcludeTable: []string{"logs"}},
+			[]string{`AND name IN ('events', 'it\'s')`, "AND name NOT IN ('logs')"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClickHouse{}.tablesQuery(tt.conf)
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, got, s)
+			}
+		})
+	}
+}
+
+func TestClickHouse_RestoreCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Restore
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Restore{Global: &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"}},
+			command.NewBuilder(
+				command.NewEnv("CLICKHOUSE_PASSWORD", "p"),
+				"clickhouse-client", "--multiquery",
+				"--host=1.1.1.1", "--user=u", "--database=d",
+				"--ignore-error",
+			),
+		},
+		{
+			"halt on error",
+			&conftypes.Restore{Global: &conftypes.Global{}, HaltOnError: true},
+			command.NewBuilder("clickhouse-client", "--multiquery"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClickHouse{}.RestoreCommand(tt.conf, sqlformat.Gzip))
+		})
+	}
+}
+
+func TestClickHouse_quoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		param string
+		want  string
+	}{
+		{"simple", "events", "`events`"},
+		{"backtick", "a`b", "`a\\`b`"},
+		{"backslash", `a\b`, "`a\\\\b`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClickHouse{}.quoteIdentifier(tt.param))
+		})
+	}
+}
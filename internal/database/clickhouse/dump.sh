@@ -0,0 +1,27 @@
+#!/usr/bin/env sh
+set -eu
+
+# client is defined by kubedb, and runs clickhouse-client with the connection flags.
+# TABLES_QUERY returns the quoted name, whether data should be dumped, and the create statement for each table.
+tables="$(client --format=TSVRaw --query="$TABLES_QUERY" </dev/null)"
+
+tab="$(printf '\t')"
+while IFS="$tab" read -r name has_data create; do
+  if [ -z "$name" ]; then
+    continue
+  fi
+
+  echo "Dumping $name" >&2
+  if [ -n "${CLEAN:-}" ]; then
+    printf 'DROP TABLE IF EXISTS %s;\n' "$name"
+  fi
+  printf '%s;\n' "$create"
+
+  if [ "$has_data" = 1 ] && [ "$(client --query="SELECT count() > 0 FROM $name" </dev/null)" = 1 ]; then
+    printf 'INSERT INTO %s VALUES ' "$name"
+    client --query="SELECT * FROM $name FORMAT Values" </dev/null
+    printf ';\n'
+  fi
+done <<END
+$tables
+END
@@ -7,12 +7,18 @@ import (
 	"strings"
 
 	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/clickhouse"
+	"github.com/clevyr/kubedb/internal/database/elasticsearch"
 	"github.com/clevyr/kubedb/internal/database/mariadb"
 	"github.com/clevyr/kubedb/internal/database/meilisearch"
 	"github.com/clevyr/kubedb/internal/database/mongodb"
+	"github.com/clevyr/kubedb/internal/database/mssql"
 	"github.com/clevyr/kubedb/internal/database/postgres"
 	"github.com/clevyr/kubedb/internal/database/redis"
 	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/database/sqlite"
+	"github.com/clevyr/kubedb/internal/encryption"
+	"github.com/clevyr/kubedb/internal/split"
 )
 
 func All() []conftypes.Database {
@@ -20,8 +26,12 @@ func All() []conftypes.Database {
 		postgres.Postgres{},
 		mariadb.MariaDB{},
 		mongodb.MongoDB{},
+		mssql.MSSQL{},
+		clickhouse.ClickHouse{},
+		elasticsearch.Elasticsearch{},
 		redis.Redis{},
 		meilisearch.Meilisearch{},
+		sqlite.SQLite{},
 	}
 }
 
@@ -62,6 +72,12 @@ func NamesForInterface[T any]() []string {
 }
 
 func DetectFormat(db conftypes.DBFiler, path string) sqlformat.Format {
+	if strings.Contains(path, "://") {
+		// Ignore URL query params, such as a presigned URL signature
+		path, _, _ = strings.Cut(path, "?")
+	}
+	path, _, _ = split.Trim(path)
+	path = strings.TrimSuffix(path, encryption.Ext)
 	for format, ext := range db.Formats() {
 		if strings.HasSuffix(path, ext) {
 			return format
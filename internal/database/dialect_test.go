@@ -4,10 +4,15 @@ import (
 	"testing"
 
 	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/clickhouse"
+	"github.com/clevyr/kubedb/internal/database/elasticsearch"
 	"github.com/clevyr/kubedb/internal/database/mariadb"
 	"github.com/clevyr/kubedb/internal/database/mongodb"
+	"github.com/clevyr/kubedb/internal/database/mssql"
 	"github.com/clevyr/kubedb/internal/database/postgres"
+	"github.com/clevyr/kubedb/internal/database/redis"
 	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/database/sqlite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,6 +36,14 @@ func TestNew(t *testing.T) {
 		{"mysql", args{"mysql"}, mariadb.MariaDB{}, require.NoError},
 		{"mongodb", args{"mongodb"}, mongodb.MongoDB{}, require.NoError},
 		{"mongo", args{"mongo"}, mongodb.MongoDB{}, require.NoError},
+		{"mssql", args{"mssql"}, mssql.MSSQL{}, require.NoError},
+		{"sqlserver", args{"sqlserver"}, mssql.MSSQL{}, require.NoError},
+		{"clickhouse", args{"clickhouse"}, clickhouse.ClickHouse{}, require.NoError},
+		{"ch", args{"ch"}, clickhouse.ClickHouse{}, require.NoError},
+		{"sqlite", args{"sqlite"}, sqlite.SQLite{}, require.NoError},
+		{"sqlite3", args{"sqlite3"}, sqlite.SQLite{}, require.NoError},
+		{"elasticsearch", args{"elasticsearch"}, elasticsearch.Elasticsearch{}, require.NoError},
+		{"opensearch", args{"opensearch"}, elasticsearch.Elasticsearch{}, require.NoError},
 		{"invalid", args{"invalid"}, nil, require.Error},
 	}
 	for _, tt := range tests {
@@ -55,11 +68,19 @@ func TestDetectFormat(t *testing.T) {
 		{"postgres plain", args{postgres.Postgres{}, "test.sql"}, sqlformat.Plain},
 		{"postgres gzipped", args{postgres.Postgres{}, "test.sql.gz"}, sqlformat.Gzip},
 		{"postgres custom", args{postgres.Postgres{}, "test.dmp"}, sqlformat.Custom},
+		{"postgres zstd", args{postgres.Postgres{}, "test.sql.zst"}, sqlformat.Zstd},
 		{"mariadb plain", args{mariadb.MariaDB{}, "test.sql"}, sqlformat.Plain},
 		{"mariadb gzipped", args{mariadb.MariaDB{}, "test.sql.gz"}, sqlformat.Gzip},
 		{"mariadb unknown", args{mariadb.MariaDB{}, "test.sql.gz"}, sqlformat.Gzip},
+		{"redis gzipped", args{redis.Redis{}, "test.redis.gz"}, sqlformat.Gzip},
+		{"redis rdb", args{redis.Redis{}, "test.rdb"}, sqlformat.Custom},
+		{"sqlite plain", args{sqlite.SQLite{}, "test.sql"}, sqlformat.Plain},
+		{"sqlite custom", args{sqlite.SQLite{}, "test.sqlite"}, sqlformat.Custom},
 		{"mongodb plain", args{mongodb.MongoDB{}, "test.archive"}, sqlformat.Plain},
 		{"mongodb gzipped", args{mongodb.MongoDB{}, "test.archive.gz"}, sqlformat.Gzip},
+		{"mongodb zstd", args{mongodb.MongoDB{}, "test.archive.zst"}, sqlformat.Zstd},
+		{"presigned url", args{postgres.Postgres{}, "https://example.com/test.sql?X-Amz-Signature=a"}, sqlformat.Plain},
+		{"encrypted", args{postgres.Postgres{}, "test.sql.gz.age"}, sqlformat.Gzip},
 		{"unknown", args{postgres.Postgres{}, "test.txt"}, sqlformat.Unknown},
 	}
 	for _, tt := range tests {
@@ -85,10 +106,12 @@ func TestGetExtension(t *testing.T) {
 		{"postgres plain", args{postgres.Postgres{}, sqlformat.Plain}, ".sql"},
 		{"postgres gzipped", args{postgres.Postgres{}, sqlformat.Gzip}, ".sql.gz"},
 		{"postgres custom", args{postgres.Postgres{}, sqlformat.Custom}, ".dmp"},
+		{"postgres zstd", args{postgres.Postgres{}, sqlformat.Zstd}, ".sql.zst"},
 		{"mariadb plain", args{mariadb.MariaDB{}, sqlformat.Plain}, ".sql"},
 		{"mariadb gzipped", args{mariadb.MariaDB{}, sqlformat.Gzip}, ".sql.gz"},
 		{"mongodb plain", args{mongodb.MongoDB{}, sqlformat.Plain}, ".archive"},
 		{"mongodb gzipped", args{mongodb.MongoDB{}, sqlformat.Gzip}, ".archive.gz"},
+		{"mongodb zstd", args{mongodb.MongoDB{}, sqlformat.Zstd}, ".archive.zst"},
 		{"unknown", args{postgres.Postgres{}, sqlformat.Unknown}, ""},
 	}
 	for _, tt := range tests {
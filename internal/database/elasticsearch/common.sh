@@ -0,0 +1,33 @@
+# shellcheck shell=sh
+# ES_COMMON is evaluated by each script, and by the requests which restore.sh runs in subshells.
+if [ -z "${ES_URL:-}" ]; then
+  # ECK and the security plugin serve HTTPS with a self-signed certificate
+  if curl -s --insecure -o /dev/null "https://$ES_HOST" </dev/null; then
+    ES_URL="https://$ES_HOST"
+  else
+    ES_URL="http://$ES_HOST"
+  fi
+  export ES_URL
+fi
+
+# es sends a request to the REST API. Extra arguments are passed to curl.
+es() {
+  method="$1" path="${2#/}"
+  shift 2
+  # shellcheck disable=SC2086
+  curl -sS --insecure -X "$method" ${ES_PASSWORD:+--user "$ES_USER:$ES_PASSWORD"} "$@" "$ES_URL/$path"
+}
+
+# request sends stdin as the request body, and reports the response if it failed.
+request() {
+  out="$(es "$1" "$2" -H 'Content-Type: application/x-ndjson' --data-binary @-)"
+  case "$out" in
+    '{"acknowledged":true'* | '{"errors":false'*) ;;
+    *)
+      printf '%s %s failed: %s\n' "$1" "$2" "$out" >&2
+      if [ -n "${HALT_ON_ERROR:-}" ]; then
+        : >"$ES_FAILED"
+      fi
+      ;;
+  esac
+}
@@ -0,0 +1,77 @@
+#!/usr/bin/env sh
+set -eu
+eval "$ES_COMMON"
+
+# Settings which are generated by the cluster, and are rejected when an index is created
+settings_filter='-*.settings.index.uuid,-*.settings.index.creation_date,-*.settings.index.provided_name,-*.settings.index.version,-*.settings.index.resize,-*.settings.index.routing.allocation.initial_recovery'
+hits_filter='_scroll_id,hits.hits._id,hits.hits._routing,hits.hits._source'
+
+# Prints a bulk index action and source line for each hit in a search response.
+# Exits with 1 when the response has no hits.
+hits_awk="$(cat <<'AWK'
+function emit() {
+  printf "{\"index\":{\"_index\":\"%s\",\"_id\":%s", index_name, val["\"_id\""]
+  if ("\"_routing\"" in val) printf ",\"routing\":%s", val["\"_routing\""]
+  printf "}}\n"
+  if ("\"_source\"" in val) print val["\"_source\""]
+  else print "{}"
+  found = 1
+}
+{
+  s = $0
+  i = index(s, "\"hits\":{\"hits\":[")
+  if (!i) exit 1
+  n = length(s)
+  depth = 0
+  for (i += 16; i <= n; i++) {
+    c = substr(s, i, 1)
+    if (str) {
+      if (c == "\\") i++
+      else if (c == "\"") str = 0
+    } else if (c == "\"") {
+      str = 1
+    } else if (c == "{" || c == "[") {
+      if (!depth) { split("", val); start = i + 1 }
+      depth++
+    } else if (c == "}" || c == "]") {
+      if (depth == 1) { val[key] = substr(s, start, i - start); emit() }
+      if (--depth < 0) break
+    } else if (depth == 1 && c == ":") {
+      key = substr(s, start, i - start)
+      start = i + 1
+    } else if (depth == 1 && c == ",") {
+      val[key] = substr(s, start, i - start)
+      start = i + 1
+    }
+  }
+}
+END { exit !found }
+AWK
+)"
+
+indices="$(es GET "_cat/indices/$INDICES?h=index&s=index&expand_wildcards=open" --fail)"
+if [ -z "$indices" ]; then
+  echo 'No indices found' >&2
+fi
+
+for index in $indices; do
+  echo "Dumping $index" >&2
+  settings="$(es GET "$index?filter_path=$settings_filter" --fail)"
+  settings="${settings#*\":}"
+  printf '{"create_index":"%s","body":%s}\n' "$index" "${settings%\}}"
+
+  resp="$(es POST "$index/_search?scroll=5m&size=500&filter_path=$hits_filter" --fail \
+    -H 'Content-Type: application/json' --data-binary '{"sort":["_doc"]}')"
+  while :; do
+    scroll_id="$(printf '%s\n' "$resp" | sed -n 's/^{"_scroll_id":"\([^"]*\)".*/\1/p')"
+    if ! printf '%s\n' "$resp" | LC_ALL=C awk -v index_name="$index" "$hits_awk"; then
+      break
+    fi
+    resp="$(es POST "_search/scroll?filter_path=$hits_filter" --fail \
+      -H 'Content-Type: application/json' --data-binary '{"scroll":"5m","scroll_id":"'"$scroll_id"'"}')"
+  done
+  if [ -n "$scroll_id" ]; then
+    es DELETE _search/scroll -o /dev/null \
+      -H 'Content-Type: application/json' --data-binary '{"scroll_id":"'"$scroll_id"'"}' || true
+  fi
+done
@@ -0,0 +1,178 @@
+package elasticsearch
+
+import (
+	_ "embed"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/kubernetes/filter"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+var (
+	_ conftypes.DBAliaser     = Elasticsearch{}
+	_ conftypes.DBDumper      = Elasticsearch{}
+	_ conftypes.DBExecer      = Elasticsearch{}
+	_ conftypes.DBRestorer    = Elasticsearch{}
+	_ conftypes.DBHasUser     = Elasticsearch{}
+	_ conftypes.DBHasPort     = Elasticsearch{}
+	_ conftypes.DBHasPassword = Elasticsearch{}
+	_ conftypes.DBTableLister = Elasticsearch{}
+)
+
+type Elasticsearch struct{}
+
+func (Elasticsearch) Name() string { return "elasticsearch" }
+
+func (Elasticsearch) PrettyName() string { return "Elasticsearch" }
+
+func (Elasticsearch) Aliases() []string { return []string{"es", "opensearch"} }
+
+func (Elasticsearch) PortEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{
+		"ELASTICSEARCH_HTTP_PORT_NUMBER",
+		"OPENSEARCH_HTTP_PORT_NUMBER",
+	}}
+}
+
+func (Elasticsearch) PortDefault() uint16 { return 9200 }
+
+func (Elasticsearch) TableListQuery() string {
+	return "GET _cat/indices?h=index&s=index&expand_wildcards=open"
+}
+
+func (db Elasticsearch) UserEnvs(conf *conftypes.Global) kubernetes.ConfigLookups {
+	lookups := kubernetes.ConfigLookups{kubernetes.LookupEnv{"ELASTICSEARCH_USERNAME", "OPENSEARCH_USERNAME"}}
+	if db.isOpenSearch(conf) {
+		lookups = append(lookups, kubernetes.LookupDefault("admin"))
+	}
+	return lookups
+}
+
+func (Elasticsearch) UserDefault() string { return "elastic" }
+
+func (db Elasticsearch) PasswordEnvs(conf *conftypes.Global) kubernetes.ConfigLookups {
+	if cluster, ok := db.eckClusterName(conf); ok && conf.Username == db.UserDefault() {
+		return kubernetes.ConfigLookups{kubernetes.LookupNamedSecret{
+			Name: cluster + "-es-elastic-user",
+			Key:  "elastic",
+		}}
+	}
+
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{
+		"ELASTIC_PASSWORD",
+		"ELASTICSEARCH_PASSWORD",
+		"OPENSEARCH_PASSWORD",
+		"OPENSEARCH_INITIAL_ADMIN_PASSWORD",
+	}}
+}
+
+func (Elasticsearch) PodFilters() filter.Filter {
+	return filter.Or{
+		// ECK operator
+		filter.Label{Name: "common.k8s.elastic.co/type", Value: "elasticsearch"},
+		filter.And{
+			filter.Label{
+				Name:     "app.kubernetes.io/name",
+				Operator: selection.In,
+				Values:   []string{"elasticsearch", "opensearch"},
+			},
+			filter.Label{
+				Name:     "app.kubernetes.io/component",
+				Operator: selection.NotIn,
+				Values:   []string{"metrics", "dashboards"},
+			},
+		},
+	}
+}
+
+func (Elasticsearch) eckClusterName(conf *conftypes.Global) (string, bool) {
+	v, ok := conf.DBPod.Labels["elasticsearch.k8s.elastic.co/cluster-name"]
+	return v, ok
+}
+
+func (Elasticsearch) isOpenSearch(conf *conftypes.Global) bool {
+	return conf.DBPod.Labels["app.kubernetes.io/name"] == "opensearch"
+}
+
+//go:embed common.sh
+var commonScript string
+
+// newCmd sets the connection details for a script. Scripts load the helpers
+// in ES_COMMON, which detect the scheme and send requests with curl.
+func (Elasticsearch) newCmd(conf *conftypes.Global, p ...any) *command.Builder {
+	cmd := command.NewBuilder(
+		command.NewEnv("ES_HOST", conf.Host+":"+strconv.Itoa(int(conf.Port))),
+		command.NewEnv("ES_COMMON", commonScript),
+	)
+	if conf.Password != "" {
+		cmd.Push(
+			command.NewEnv("ES_USER", conf.Username),
+			command.NewEnv("ES_PASSWORD", conf.Password),
+		)
+	}
+	return cmd.Push(p...)
+}
+
+//go:embed exec.sh
+var execScript string
+
+// ExecCommand opens a shell which sends requests like "GET _cat/indices?v".
+func (db Elasticsearch) ExecCommand(conf *conftypes.Exec) *command.Builder {
+	cmd := db.newCmd(conf.Global, "exec", "sh", "-c", execScript)
+	if conf.Command != "" {
+		cmd.Push("sh", conf.Command)
+	}
+	return cmd
+}
+
+//go:embed dump.sh
+var dumpScript string
+
+// DumpCommand writes NDJSON with the settings and mappings of each index,
+// followed by its documents as bulk API actions.
+func (db Elasticsearch) DumpCommand(conf *conftypes.Dump) *command.Builder {
+	cmd := db.newCmd(conf.Global, "sh", "-c", dumpScript)
+	cmd.Unshift(command.NewEnv("INDICES", db.indices(conf)))
+	return cmd
+}
+
+// indices returns a multi-target index expression. System and hidden
+// indices are excluded unless they are named explicitly.
+func (Elasticsearch) indices(conf *conftypes.Dump) string {
+	targets := []string{"*", "-.*"}
+	if len(conf.Table) != 0 {
+		targets = slices.Clone(conf.Table)
+	}
+	for _, index := range conf.ExcludeTable {
+		targets = append(targets, "-"+index)
+	}
+	return strings.Join(targets, ",")
+}
+
+//go:embed restore.sh
+var restoreScript string
+
+func (db Elasticsearch) RestoreCommand(conf *conftypes.Restore, _ sqlformat.Format) *command.Builder {
+	cmd := db.newCmd(conf.Global, "sh", "-c", restoreScript)
+	if conf.Clean {
+		cmd.Unshift(command.NewEnv("CLEAN", "true"))
+	}
+	if conf.HaltOnError {
+		cmd.Unshift(command.NewEnv("HALT_ON_ERROR", "true"))
+	}
+	return cmd
+}
+
+func (Elasticsearch) Formats() map[sqlformat.Format]string {
+	return map[sqlformat.Format]string{
+		sqlformat.Plain: ".ndjson",
+		sqlformat.Gzip:  ".ndjson.gz",
+		sqlformat.Zstd:  ".ndjson.zst",
+	}
+}
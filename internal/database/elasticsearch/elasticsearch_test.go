@@ -0,0 +1,108 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestElasticsearch_PasswordEnvs(t *testing.T) {
+	eckPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{"elasticsearch.k8s.elastic.co/cluster-name": "search"},
+	}}
+
+	tests := []struct {
+		name string
+		conf *conftypes.Global
+		want kubernetes.ConfigLookups
+	}{
+		{
+			"default",
+			&conftypes.Global{},
+			kubernetes.ConfigLookups{kubernetes.LookupEnv{
+				"ELASTIC_PASSWORD",
+				"ELASTICSEARCH_PASSWORD",
+				"OPENSEARCH_PASSWORD",
+				"OPENSEARCH_INITIAL_ADMIN_PASSWORD",
+			}},
+		},
+		{
+			"eck",
+			&conftypes.Global{DBPod: eckPod, Username: "elastic"},
+			kubernetes.ConfigLookups{kubernetes.LookupNamedSecret{Name: "search-es-elastic-user", Key: "elastic"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Elasticsearch{}.PasswordEnvs(tt.conf))
+		})
+	}
+}
+
+func TestElasticsearch_ExecCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Exec
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "1.1.1.1", Port: 9200}},
+			command.NewBuilder(
+				command.NewEnv("ES_HOST", "1.1.1.1:9200"), command.NewEnv("ES_COMMON", commonScript),
+				"exec", "sh", "-c", execScript,
+			),
+		},
+		{
+			"password and command",
+			&conftypes.Exec{
+				Global:  &conftypes.Global{Host: "1.1.1.1", Port: 9200, Username: "elastic", Password: "p"},
+				Command: "GET _cat/indices",
+			},
+			command.NewBuilder(
+				command.NewEnv("ES_HOST", "1.1.1.1:9200"), command.NewEnv("ES_COMMON", commonScript),
+				command.NewEnv("ES_USER", "elastic"), command.NewEnv("ES_PASSWORD", "p"),
+				"exec", "sh", "-c", execScript, "sh", "GET _cat/indices",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Elasticsearch{}.ExecCommand(tt.conf))
+		})
+	}
+}
+
+func TestElasticsearch_indices(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Dump
+		want string
+	}{
+		{"default", &conftypes.Dump{}, "*,-.*"},
+		{"exclude", &conftypes.Dump{ExcludeTable: []string{"logs-*"}}, "*,-.*,-logs-*"},
+		{"table", &conftypes.Dump{Table: []string{"users", "orders"}}, "users,orders"},
+		{"table and exclude", &conftypes.Dump{Table: []string{"logs-*"}, ExcludeTable: []string{"logs-old"}}, "logs-*,-logs-old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Elasticsearch{}.indices(tt.conf))
+		})
+	}
+}
+
+func TestElasticsearch_RestoreCommand(t *testing.T) {
+	conf := &conftypes.Restore{Global: &conftypes.Global{Host: "1.1.1.1", Port: 9200}, Clean: true, HaltOnError: true}
+	want := command.NewBuilder(
+		command.NewEnv("HALT_ON_ERROR", "true"), command.NewEnv("CLEAN", "true"),
+		command.NewEnv("ES_HOST", "1.1.1.1:9200"), command.NewEnv("ES_COMMON", commonScript),
+		"sh", "-c", restoreScript,
+	)
+	assert.Equal(t, want, Elasticsearch{}.RestoreCommand(conf, sqlformat.Gzip))
+}
@@ -0,0 +1,30 @@
+#!/usr/bin/env sh
+set -eu
+eval "$ES_COMMON"
+
+# run sends a request like "GET _cat/indices?v" with an optional JSON body.
+run() {
+  method="$(printf '%s' "$1" | tr '[:lower:]' '[:upper:]')"
+  if [ -n "$3" ]; then
+    out="$(es "$method" "$2" -H 'Content-Type: application/json' --data-binary "$3")"
+  else
+    out="$(es "$method" "$2")"
+  fi
+  printf '%s\n' "$out"
+}
+
+if [ $# -gt 0 ]; then
+  printf '%s\n' "$1" | {
+    read -r method path body
+    run "$method" "$path" "$body"
+  }
+  exit
+fi
+
+echo 'Enter requests as "METHOD path [body]", for example "GET _cat/indices?v"' >&2
+while printf 'es> ' >&2 && read -r method path body; do
+  if [ -n "$method" ]; then
+    run "$method" "$path" "$body" || true
+  fi
+done
+echo >&2
@@ -0,0 +1,52 @@
+#!/usr/bin/env sh
+set -eu
+eval "$ES_COMMON"
+
+ES_FAILED="$(mktemp)"
+rm -f "$ES_FAILED"
+export ES_FAILED
+trap 'rm -f "$ES_FAILED"' EXIT
+
+# Creates each index as it is read, and sends documents to the bulk API in batches.
+# Requests run in a subshell which loads ES_COMMON.
+restore_awk="$(cat <<'AWK'
+function quote(s) {
+  gsub(/'/, "'\"'\"'", s)
+  return "'" s "'"
+}
+function check() {
+  if (system("test ! -e \"$ES_FAILED\"")) { halted = 1; exit 1 }
+}
+function flush() {
+  if (lines) { close(bulk); lines = 0; check() }
+}
+BEGIN {
+  run = "sh -c 'eval \"$ES_COMMON\"; \"$@\"' sh"
+  bulk = run " request POST " quote("_bulk?filter_path=errors,items.*.error")
+}
+/^\{"create_index":"/ {
+  flush()
+  name = $0
+  sub(/^\{"create_index":"/, "", name)
+  sub(/".*/, "", name)
+  body = $0
+  sub(/^\{"create_index":"[^"]*","body":/, "", body)
+  sub(/\}$/, "", body)
+
+  printf "Restoring %s\n", name > "/dev/stderr"
+  if (clean) system(run " es DELETE " quote(name) " -o /dev/null </dev/null")
+  cmd = run " request PUT " quote(name)
+  print body | cmd
+  close(cmd)
+  check()
+  next
+}
+NF {
+  print | bulk
+  if (++lines >= batch * 2) flush()
+}
+END { if (!halted) flush() }
+AWK
+)"
+
+LC_ALL=C awk -v clean="${CLEAN:-}" -v batch=1000 "$restore_awk"
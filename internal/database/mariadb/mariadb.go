@@ -165,6 +165,7 @@ func (MariaDB) Formats() map[sqlformat.Format]string {
 	return map[sqlformat.Format]string{
 		sqlformat.Plain: ".sql",
 		sqlformat.Gzip:  ".sql.gz",
+		sqlformat.Zstd:  ".sql.zst",
 	}
 }
 
@@ -71,6 +71,7 @@ func (Meilisearch) Formats() map[sqlformat.Format]string {
 	return map[sqlformat.Format]string{
 		sqlformat.Plain: ".dump",
 		sqlformat.Gzip:  ".dump.gz",
+		sqlformat.Zstd:  ".dump.zst",
 	}
 }
 
@@ -191,5 +191,6 @@ func (MongoDB) Formats() map[sqlformat.Format]string {
 	return map[sqlformat.Format]string{
 		sqlformat.Plain: ".archive",
 		sqlformat.Gzip:  ".archive.gz",
+		sqlformat.Zstd:  ".archive.zst",
 	}
 }
@@ -0,0 +1,14 @@
+#!/usr/bin/env sh
+set -eu
+
+# client is defined by kubedb, and runs sqlcmd with the connection flags.
+# The server writes the backup, so it is created next to the database files.
+dir="$(client -h -1 -W -Q "SET NOCOUNT ON; SELECT CONVERT(nvarchar(260), SERVERPROPERTY('InstanceDefaultDataPath'))" </dev/null)"
+file="${dir}kubedb-$$.bak"
+trap 'rm -f "$file"' EXIT
+
+echo 'Creating backup' >&2
+client -Q "$BACKUP_QUERY" -v file="$file" </dev/null >&2
+
+echo 'Downloading backup' >&2
+cat "$file"
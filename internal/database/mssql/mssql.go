@@ -0,0 +1,183 @@
+package mssql
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/kubernetes/filter"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+var (
+	_ conftypes.DBAliaser        = MSSQL{}
+	_ conftypes.DBDumper         = MSSQL{}
+	_ conftypes.DBExecer         = MSSQL{}
+	_ conftypes.DBRestorer       = MSSQL{}
+	_ conftypes.DBHasUser        = MSSQL{}
+	_ conftypes.DBHasPort        = MSSQL{}
+	_ conftypes.DBHasPassword    = MSSQL{}
+	_ conftypes.DBHasDatabase    = MSSQL{}
+	_ conftypes.DBDatabaseLister = MSSQL{}
+	_ conftypes.DBTableLister    = MSSQL{}
+	_ conftypes.DBCanDisableJob  = MSSQL{}
+)
+
+type MSSQL struct{}
+
+func (MSSQL) Name() string { return "mssql" }
+
+func (MSSQL) PrettyName() string { return "SQL Server" }
+
+func (MSSQL) Aliases() []string { return []string{"sqlserver"} }
+
+func (MSSQL) PortEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"MSSQL_TCP_PORT"}}
+}
+
+func (MSSQL) PortDefault() uint16 { return 1433 }
+
+func (MSSQL) DatabaseEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"MSSQL_DATABASE", "MSSQL_DB"}}
+}
+
+func (MSSQL) DatabaseListQuery() string {
+	return "SELECT name FROM sys.databases WHERE database_id > 4 ORDER BY name"
+}
+
+func (MSSQL) TableListQuery() string {
+	return "SELECT name FROM sys.tables ORDER BY name"
+}
+
+func (MSSQL) UserEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{}
+}
+
+func (MSSQL) UserDefault() string { return "sa" }
+
+func (MSSQL) PasswordEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupEnv{"MSSQL_SA_PASSWORD", "SA_PASSWORD"}}
+}
+
+func (MSSQL) PodFilters() filter.Filter {
+	return filter.Or{
+		filter.Image{"mcr.microsoft.com/mssql/server", "mcr.microsoft.com/mssql/rhel/server"},
+		filter.Label{
+			Name:     "app.kubernetes.io/name",
+			Operator: selection.In,
+			Values:   []string{"mssql", "mssql-server", "sqlserver"},
+		},
+		filter.Label{Name: "app", Value: "mssql"},
+	}
+}
+
+// DisableJob is required since the server reads and writes backups on its own filesystem.
+func (MSSQL) DisableJob() bool {
+	return true
+}
+
+func (MSSQL) newCmd(conf *conftypes.Global, p ...any) *command.Builder {
+	cmd := command.NewBuilder(p...)
+	if conf.Password != "" {
+		cmd.Unshift(command.NewEnv("SQLCMDPASSWORD", conf.Password))
+	}
+	// mssql-tools18 is installed in newer images
+	cmd.Push(
+		command.Raw(`"$(command -v sqlcmd || ls /opt/mssql-tools*/bin/sqlcmd | tail -n 1)"`),
+		"-C",
+		"-S", conf.Host+","+strconv.Itoa(int(conf.Port)),
+	)
+	if conf.Username != "" {
+		cmd.Push("-U", conf.Username)
+	}
+	if conf.Database != "" {
+		cmd.Push("-d", conf.Database)
+	}
+	return cmd
+}
+
+func (db MSSQL) ExecCommand(conf *conftypes.Exec) *command.Builder {
+	cmd := db.newCmd(conf.Global, "exec")
+	query := conf.Command
+	if conf.DisableHeaders {
+		cmd.Push("-h", "-1", "-W")
+		if query != "" {
+			query = "SET NOCOUNT ON; " + query
+		}
+	}
+	if query != "" {
+		cmd.Push("-Q", query)
+	}
+	return cmd
+}
+
+// client prepends a shell function to a script, which runs sqlcmd with the connection flags.
+func (db MSSQL) client(conf *conftypes.Global, script string) string {
+	client := db.newCmd(conf)
+	client.Push("-b")
+	return "client() { " + client.String() + ` "$@"; }` + "\n" + script
+}
+
+//go:embed dump.sh
+var dumpScript string
+
+// DumpCommand creates a copy-only backup in the pod, then streams the .bak file.
+func (db MSSQL) DumpCommand(conf *conftypes.Dump) *command.Builder {
+	return command.NewBuilder(
+		command.NewEnv("BACKUP_QUERY", db.backupQuery(conf.Database)),
+		"sh", "-c", db.client(conf.Global, dumpScript),
+	)
+}
+
+// backupQuery writes the database to the file in the sqlcmd "file" variable.
+func (db MSSQL) backupQuery(database string) string {
+	return "BACKUP DATABASE " + db.quoteIdentifier(database) +
+		" TO DISK = N'$(file)' WITH COPY_ONLY, INIT, STATS = 10"
+}
+
+//go:embed restore.sh
+var restoreScript string
+
+// RestoreCommand streams a .bak file into the pod, then restores it over the database.
+func (db MSSQL) RestoreCommand(conf *conftypes.Restore, _ sqlformat.Format) *command.Builder {
+	global := *conf.Global
+	// The target database may not exist yet
+	global.Database = ""
+
+	return command.NewBuilder(
+		command.NewEnv("DB_NAME", conf.Database),
+		command.NewEnv("FILELIST_QUERY", "SET NOCOUNT ON; RESTORE FILELISTONLY FROM DISK = N'$(file)'"),
+		command.NewEnv("RESTORE_QUERY", db.restoreQuery(conf.Database)),
+		"sh", "-c", db.client(&global, restoreScript),
+	)
+}
+
+// restoreQuery closes connections to the database, then restores the file in the
+// sqlcmd "file" variable. The database is reopened if the restore fails.
+func (db MSSQL) restoreQuery(database string) string {
+	name := db.quoteString(database)
+	ident := db.quoteIdentifier(database)
+	return "IF DB_ID(" + name + ") IS NOT NULL ALTER DATABASE " + ident + " SET SINGLE_USER WITH ROLLBACK IMMEDIATE; " +
+		"BEGIN TRY RESTORE DATABASE " + ident + " FROM DISK = N'$(file)' WITH REPLACE, $(options) END TRY " +
+		"BEGIN CATCH IF DB_ID(" + name + ") IS NOT NULL ALTER DATABASE " + ident + " SET MULTI_USER; THROW; END CATCH"
+}
+
+func (MSSQL) Formats() map[sqlformat.Format]string {
+	return map[sqlformat.Format]string{
+		sqlformat.Plain: ".bak",
+		sqlformat.Gzip:  ".bak.gz",
+		sqlformat.Zstd:  ".bak.zst",
+	}
+}
+
+func (MSSQL) quoteIdentifier(param string) string {
+	return "[" + strings.ReplaceAll(param, "]", "]]") + "]"
+}
+
+func (MSSQL) quoteString(param string) string {
+	return "N'" + strings.ReplaceAll(param, "'", "''") + "'"
+}
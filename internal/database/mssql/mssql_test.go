@@ -0,0 +1,95 @@
+package mssql
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const sqlcmd = command.Raw(`"$(command -v sqlcmd || ls /opt/mssql-tools*/bin/sqlcmd | tail -n 1)"`)
+
+func TestMSSQL_PodFilters(t *testing.T) {
+	pod := corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Image: "mcr.microsoft.com/mssql/server:2022-latest"},
+	}}}
+	assert.True(t, MSSQL{}.PodFilters().Matches(pod))
+}
+
+func TestMSSQL_ExecCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Exec
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "127.0.0.1", Port: 1433, Username: "sa", Password: "p", Database: "d"}},
+			command.NewBuilder(
+				command.NewEnv("SQLCMDPASSWORD", "p"), "exec",
+				sqlcmd, "-C", "-S", "127.0.0.1,1433", "-U", "sa", "-d", "d",
+			),
+		},
+		{
+			"command",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "127.0.0.1", Port: 1433}, Command: "SELECT 1"},
+			command.NewBuilder("exec", sqlcmd, "-C", "-S", "127.0.0.1,1433", "-Q", "SELECT 1"),
+		},
+		{
+			"disable headers",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "127.0.0.1", Port: 1433}, DisableHeaders: true, Command: "SELECT 1"},
+			command.NewBuilder(
+				"exec", sqlcmd, "-C", "-S", "127.0.0.1,1433",
+				"-h", "-1", "-W", "-Q", "SET NOCOUNT ON; SELECT 1",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MSSQL{}.ExecCommand(tt.conf))
+		})
+	}
+}
+
+func TestMSSQL_DumpCommand(t *testing.T) {
+	conf := &conftypes.Dump{Global: &conftypes.Global{Host: "127.0.0.1", Port: 1433, Username: "sa", Database: "d"}}
+	got := MSSQL{}.DumpCommand(conf).String()
+	assert.Contains(t, got, "BACKUP_QUERY=")
+	assert.Contains(t, got, "client() { ")
+	assert.Contains(t, got, " -d d -b ")
+}
+
+func TestMSSQL_backupQuery(t *testing.T) {
+	assert.Equal(t,
+		"BACKUP DATABASE [a]]b] TO DISK = N'$(file)' WITH COPY_ONLY, INIT, STATS = 10",
+		MSSQL{}.backupQuery("a]b"),
+	)
+}
+
+func TestMSSQL_RestoreCommand(t *testing.T) {
+	conf := &conftypes.Restore{Global: &conftypes.Global{Host: "127.0.0.1", Port: 1433, Username: "sa", Database: "d"}}
+	got := MSSQL{}.RestoreCommand(conf, sqlformat.Gzip).String()
+	assert.Contains(t, got, "DB_NAME=d ")
+	assert.Contains(t, got, "RESTORE_QUERY=")
+	// The database is not selected since it may not exist
+	assert.NotContains(t, got, " -d ")
+	assert.Equal(t, "d", conf.Database)
+}
+
+func TestMSSQL_restoreQuery(t *testing.T) {
+	got := MSSQL{}.restoreQuery("o'brien")
+	assert.Contains(t, got, "IF DB_ID(N'o''brien') IS NOT NULL ALTER DATABASE [o'brien] SET SINGLE_USER")
+	assert.Contains(t, got, "RESTORE DATABASE [o'brien] FROM DISK = N'$(file)' WITH REPLACE, $(options)")
+	assert.Contains(t, got, "SET MULTI_USER; THROW;")
+}
+
+func TestMSSQL_quoteIdentifier(t *testing.T) {
+	assert.Equal(t, "[a]]b]", MSSQL{}.quoteIdentifier("a]b"))
+}
+
+func TestMSSQL_quoteString(t *testing.T) {
+	assert.Equal(t, "N'o''brien'", MSSQL{}.quoteString("o'brien"))
+}
@@ -0,0 +1,35 @@
+#!/usr/bin/env sh
+set -eu
+
+# client is defined by kubedb, and runs sqlcmd with the connection flags.
+# The server reads the backup, so it is uploaded next to the database files.
+dirs="$(client -h -1 -W -Q "SET NOCOUNT ON; SELECT CONVERT(nvarchar(260), SERVERPROPERTY('InstanceDefaultDataPath')) + '|' + CONVERT(nvarchar(260), SERVERPROPERTY('InstanceDefaultLogPath'))" </dev/null)"
+data_dir="${dirs%%|*}"
+log_dir="${dirs#*|}"
+file="${data_dir}kubedb-$$.bak"
+trap 'rm -f "$file"' EXIT
+
+echo 'Uploading backup' >&2
+cat >"$file"
+
+sql_string() {
+  printf "N'%s'" "$(printf '%s' "$1" | sed "s/'/''/g")"
+}
+
+# Move each file in the backup to the default paths, named after the target database.
+# This prevents conflicts when the source database exists on the same server.
+files="$(client -h -1 -W -s '|' -Q "$FILELIST_QUERY" -v file="$file" </dev/null)"
+options='STATS = 10'
+while IFS='|' read -r logical _ type _; do
+  case "$type" in
+    D) path="$data_dir${DB_NAME}_$logical.mdf" ;;
+    L) path="$log_dir${DB_NAME}_$logical.ldf" ;;
+    *) continue ;;
+  esac
+  options="$options, MOVE $(sql_string "$logical") TO $(sql_string "$path")"
+done <<END
+$files
+END
+
+echo 'Restoring backup' >&2
+client -Q "$RESTORE_QUERY" -v file="$file" -v options="$options" </dev/null >&2
@@ -310,9 +310,18 @@ func (db Postgres) RestoreCommand(conf *conftypes.Restore, inputFormat sqlformat
 	if conf.Quiet {
 		cmd.Unshift(command.NewEnv("PGOPTIONS", "-c client_min_messages=WARNING"))
 	}
-	if conf.SingleTransaction {
+	// Parallel restores must read from a file
+	parallel := inputFormat == sqlformat.Custom && conf.File != "" && conf.Jobs > 1
+	// pg_restore cannot run parallel jobs in a single transaction
+	if conf.SingleTransaction && !parallel {
 		cmd.Push("--single-transaction")
 	}
+	if inputFormat == sqlformat.Custom && conf.File != "" {
+		if parallel {
+			cmd.Push("--jobs=" + strconv.Itoa(conf.Jobs))
+		}
+		cmd.Push(conf.File)
+	}
 	return cmd
 }
 
@@ -321,6 +330,7 @@ func (Postgres) Formats() map[sqlformat.Format]string {
 		sqlformat.Plain:  ".sql",
 		sqlformat.Gzip:   ".sql.gz",
 		sqlformat.Custom: ".dmp",
+		sqlformat.Zstd:   ".sql.zst",
 	}
 }
 
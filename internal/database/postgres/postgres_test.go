@@ -523,6 +523,65 @@ func TestPostgres_RestoreCommand(t *testing.T) {
 				"--verbose",
 			),
 		},
+		{
+			"custom staged jobs",
+			args{
+				&conftypes.Restore{
+					Jobs:   4,
+					File:   "/kubedb-stage/restore",
+					Global: &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"},
+				},
+				sqlformat.Custom,
+			},
+			command.NewBuilder(
+				pgpassword,
+				"pg_restore",
+				"--format=custom",
+				"--host=1.1.1.1",
+				"--username=u",
+				"--dbname=d",
+				"--verbose",
+				"--jobs=4",
+				"/kubedb-stage/restore",
+			),
+		},
+		{
+			"custom staged jobs default flags",
+			args{
+				&conftypes.Restore{
+					Jobs:              4,
+					File:              "/kubedb-stage/restore",
+					SingleTransaction: true,
+					Clean:             true,
+					Global:            &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"},
+				},
+				sqlformat.Custom,
+			},
+			command.NewBuilder(
+				pgpassword,
+				"pg_restore",
+				"--format=custom",
+				"--host=1.1.1.1",
+				"--username=u",
+				"--dbname=d",
+				"--clean",
+				"--verbose",
+				"--jobs=4",
+				"/kubedb-stage/restore",
+			),
+		},
+		{
+			"plain staged",
+			args{
+				&conftypes.Restore{
+					Jobs:   4,
+					File:   "/kubedb-stage/restore",
+					Global: &conftypes.Global{Host: "1.1.1.1", Database: "d", Username: "u", Password: "p"},
+				},
+				sqlformat.Plain,
+			},
+			command.NewBuilder(pgpassword, "psql", "--host=1.1.1.1", "--username=u", "--dbname=d"),
+		},
 		{
 			"sql-quiet",
 			args{
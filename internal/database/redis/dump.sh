@@ -0,0 +1,45 @@
+#!/usr/bin/env sh
+set -eu
+
+# The arguments are the redis-cli command used to connect.
+# Each batch returns the next SCAN cursor on the first line, followed by a
+# RESTORE command for each key in the Redis protocol, ready for redis-cli --pipe.
+script='
+local r = redis.call("SCAN", ARGV[1], "COUNT", 1000)
+local out = {r[1], "\n"}
+for _, key in ipairs(r[2]) do
+  local ttl = redis.call("PTTL", key)
+  local payload = redis.call("DUMP", key)
+  if ttl ~= -2 and payload then
+    if ttl < 0 then ttl = 0 end
+    ttl = tostring(ttl)
+    out[#out + 1] = "*5\r\n$7\r\nRESTORE\r\n$" .. #key .. "\r\n" .. key ..
+      "\r\n$" .. #ttl .. "\r\n" .. ttl ..
+      "\r\n$" .. #payload .. "\r\n" .. payload .. "\r\n$7\r\nREPLACE\r\n"
+  end
+end
+return table.concat(out)
+'
+
+tmp="$(mktemp)"
+trap 'rm -f "$tmp"' EXIT
+
+cursor=0
+while :; do
+  "$@" --raw EVAL "$script" 0 "$cursor" </dev/null >"$tmp"
+  cursor="$(head -n 1 "$tmp")"
+  case "$cursor" in
+    '' | *[!0-9]*)
+      cat "$tmp" >&2
+      exit 1
+      ;;
+  esac
+
+  # Skip the cursor, and the newline added by redis-cli
+  size="$(wc -c <"$tmp")"
+  tail -c "+$((${#cursor} + 2))" "$tmp" | head -c "$((size - ${#cursor} - 2))"
+
+  if [ "$cursor" = 0 ]; then
+    break
+  fi
+done
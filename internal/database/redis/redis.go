@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/clevyr/kubedb/internal/command"
 	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
 	"github.com/clevyr/kubedb/internal/kubernetes"
 	"github.com/clevyr/kubedb/internal/kubernetes/filter"
 	corev1 "k8s.io/api/core/v1"
@@ -16,11 +18,14 @@ import (
 )
 
 var (
-	_ conftypes.DBAliaser     = Redis{}
-	_ conftypes.DBExecer      = Redis{}
-	_ conftypes.DBHasPort     = Redis{}
-	_ conftypes.DBHasPassword = Redis{}
-	_ conftypes.DBHasDatabase = Redis{}
+	_ conftypes.DBAliaser         = Redis{}
+	_ conftypes.DBDatabaseDropper = Redis{}
+	_ conftypes.DBDumper          = Redis{}
+	_ conftypes.DBExecer          = Redis{}
+	_ conftypes.DBRestorer        = Redis{}
+	_ conftypes.DBHasPort         = Redis{}
+	_ conftypes.DBHasPassword     = Redis{}
+	_ conftypes.DBHasDatabase     = Redis{}
 )
 
 type Redis struct{}
@@ -121,16 +126,20 @@ func (db Redis) PasswordEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
 	}
 }
 
-func (Redis) ExecCommand(conf *conftypes.Exec) *command.Builder {
-	cmd := command.NewBuilder(
-		"exec", command.Raw(`"$(which redis-cli || which valkey-cli)"`), "-h", conf.Host,
-	)
+func (Redis) newCmd(conf *conftypes.Global, p ...any) *command.Builder {
+	cmd := command.NewBuilder(p...)
 	if conf.Password != "" {
 		cmd.Unshift(command.NewEnv("REDISCLI_AUTH", conf.Password))
 	}
+	cmd.Push(command.Raw(`"$(which redis-cli || which valkey-cli)"`), "-h", conf.Host)
 	if conf.Port != 0 {
 		cmd.Push("-p", strconv.Itoa(int(conf.Port)))
 	}
+	return cmd
+}
+
+func (db Redis) ExecCommand(conf *conftypes.Exec) *command.Builder {
+	cmd := db.newCmd(conf.Global, "exec")
 	if conf.Database != "" {
 		cmd.Push("-n", conf.Database)
 	}
@@ -143,6 +152,70 @@ func (Redis) ExecCommand(conf *conftypes.Exec) *command.Builder {
 	return cmd
 }
 
+// DatabaseDropQuery is sent inline before the RESTORE commands. redis-cli
+// selects the database, so it is not needed here.
+func (Redis) DatabaseDropQuery(_ string) string {
+	return "FLUSHDB\r\n"
+}
+
+//go:embed dump.sh
+var dumpScript string
+
+// DumpCommand streams an RDB snapshot of every database for the custom format.
+// Other formats write RESTORE commands for each key in the selected database.
+func (db Redis) DumpCommand(conf *conftypes.Dump) *command.Builder {
+	if conf.Format == sqlformat.Custom {
+		return db.newCmd(conf.Global).Push("--rdb", "-")
+	}
+
+	cmd := db.newCmd(conf.Global, "sh", "-c", dumpScript, "sh")
+	if conf.Database != "" {
+		cmd.Push("-n", conf.Database)
+	}
+	return cmd
+}
+
+//go:embed restore.sh
+var restoreScript string
+
+// RestoreCommand pipes RESTORE commands into redis-cli. RDB files are loaded
+// into a temporary server in the job, then copied the same way.
+func (db Redis) RestoreCommand(conf *conftypes.Restore, inputFormat sqlformat.Format) *command.Builder {
+	if inputFormat == sqlformat.Custom {
+		cmd := command.NewBuilder(
+			command.NewEnv("DUMP_SCRIPT", dumpScript),
+			"sh", "-c", db.client(conf.Global, restoreScript),
+		)
+		if conf.Database != "" {
+			cmd.Unshift(command.NewEnv("DB_NUM", conf.Database))
+		}
+		if conf.Clean {
+			cmd.Unshift(command.NewEnv("CLEAN", "true"))
+		}
+		return cmd
+	}
+
+	cmd := db.newCmd(conf.Global)
+	if conf.Database != "" {
+		cmd.Push("-n", conf.Database)
+	}
+	return cmd.Push("--pipe")
+}
+
+// client prepends a shell function to a script, which runs redis-cli with the connection flags.
+func (db Redis) client(conf *conftypes.Global, script string) string {
+	return "client() { " + db.newCmd(conf).String() + ` "$@"; }` + "\n" + script
+}
+
+func (Redis) Formats() map[sqlformat.Format]string {
+	return map[sqlformat.Format]string{
+		sqlformat.Plain:  ".redis",
+		sqlformat.Gzip:   ".redis.gz",
+		sqlformat.Zstd:   ".redis.zst",
+		sqlformat.Custom: ".rdb",
+	}
+}
+
 func (Redis) sentinelQuery() filter.And {
 	return filter.And{
 		filter.Label{
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+)
+
+const redisCLI = command.Raw(`"$(which redis-cli || which valkey-cli)"`)
+
+func TestRedis_ExecCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Exec
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "1.1.1.1", Port: 6379, Password: "p", Database: "1"}},
+			command.NewBuilder(
+				command.NewEnv("REDISCLI_AUTH", "p"), "exec",
+				redisCLI, "-h", "1.1.1.1", "-p", "6379", "-n", "1",
+			),
+		},
+		{
+			"command",
+			&conftypes.Exec{Global: &conftypes.Global{Host: "1.1.1.1"}, DisableHeaders: true, Command: "GET k"},
+			command.NewBuilder("exec", redisCLI, "-h", "1.1.1.1", "--raw", command.Split("GET k")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redis{}.ExecCommand(tt.conf))
+		})
+	}
+}
+
+func TestRedis_DumpCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Dump
+		want *command.Builder
+	}{
+		{
+			"logical",
+			&conftypes.Dump{Global: &conftypes.Global{Host: "1.1.1.1", Port: 6379, Database: "2"}},
+			command.NewBuilder(
+				"sh", "-c", dumpScript, "sh",
+				redisCLI, "-h", "1.1.1.1", "-p", "6379", "-n", "2",
+			),
+		},
+		{
+			"rdb",
+			&conftypes.Dump{
+				Global: &conftypes.Global{Host: "1.1.1.1", Port: 6379, Password: "p", Database: "2"},
+				Format: sqlformat.Custom,
+			},
+			command.NewBuilder(
+				command.NewEnv("REDISCLI_AUTH", "p"),
+				redisCLI, "-h", "1.1.1.1", "-p", "6379", "--rdb", "-",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redis{}.DumpCommand(tt.conf))
+		})
+	}
+}
+
+func TestRedis_RestoreCommand(t *testing.T) {
+	t.Run("logical", func(t *testing.T) {
+		conf := &conftypes.Restore{Global: &conftypes.Global{Host: "1.1.1.1", Port: 6379, Database: "2"}}
+		assert.Equal(t,
+			command.NewBuilder(redisCLI, "-h", "1.1.1.1", "-p", "6379", "-n", "2", "--pipe"),
+			Redis{}.RestoreCommand(conf, sqlformat.Gzip),
+		)
+	})
+
+	t.Run("rdb", func(t *testing.T) {
+		conf := &conftypes.Restore{Global: &conftypes.Global{Host: "1.1.1.1", Port: 6379, Password: "p", Database: "2"}, Clean: true}
+		got := Redis{}.RestoreCommand(conf, sqlformat.Custom).String()
+		assert.Contains(t, got, "CLEAN=true DB_NUM=2 DUMP_SCRIPT=")
+		assert.Contains(t, got, "client() { REDISCLI_AUTH=p ")
+	})
+}
+
+func TestRedis_DatabaseDropQuery(t *testing.T) {
+	assert.Equal(t, "FLUSHDB\r\n", Redis{}.DatabaseDropQuery("1"))
+}
@@ -0,0 +1,51 @@
+#!/usr/bin/env sh
+set -eu
+
+# client is defined by kubedb, and runs redis-cli with the connection flags.
+# The RDB file is loaded by a temporary server in the job, then its keys are
+# copied with RESTORE, so the target server does not need to be restarted.
+dir="$(mktemp -d)"
+trap 'if [ -n "${pid:-}" ]; then kill "$pid" 2>/dev/null || true; fi; rm -rf "$dir"' EXIT
+
+echo 'Uploading RDB file' >&2
+cat >"$dir/dump.rdb"
+
+echo 'Loading RDB file' >&2
+"$(which redis-server || which valkey-server)" \
+  --port 0 --unixsocket "$dir/redis.sock" \
+  --dir "$dir" --dbfilename dump.rdb \
+  --save '' --appendonly no --loglevel warning >&2 &
+pid="$!"
+
+cli="$(which redis-cli || which valkey-cli)"
+until [ "$("$cli" -s "$dir/redis.sock" PING 2>/dev/null)" = PONG ]; do
+  if ! kill -0 "$pid" 2>/dev/null; then
+    echo 'Failed to load RDB file' >&2
+    exit 1
+  fi
+  sleep 1
+done
+
+if [ -n "${DB_NUM:-}" ]; then
+  dbs="$DB_NUM"
+else
+  dbs="$("$cli" -s "$dir/redis.sock" INFO keyspace | sed -n 's/^db\([0-9]*\):.*/\1/p')"
+fi
+
+if [ -n "${CLEAN:-}" ]; then
+  echo 'Cleaning existing data' >&2
+  if [ -n "${DB_NUM:-}" ]; then
+    client -n "$DB_NUM" FLUSHDB </dev/null >&2
+  else
+    client FLUSHALL </dev/null >&2
+  fi
+fi
+
+for db in $dbs; do
+  echo "Restoring database $db" >&2
+  { sh -c "$DUMP_SCRIPT" sh "$cli" -s "$dir/redis.sock" -n "$db" || touch "$dir/failed"; } |
+    client -n "$db" --pipe
+  if [ -e "$dir/failed" ]; then
+    exit 1
+  fi
+done
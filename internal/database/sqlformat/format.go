@@ -16,6 +16,7 @@ const (
 	Gzip                  // gzip
 	Plain                 // plain
 	Custom                // custom
+	Zstd                  // zstd
 )
 
 func (i *Format) Type() string {
@@ -42,6 +43,8 @@ func ParseFormat(format string) (Format, error) {
 		return Plain, nil
 	case Custom.String(), "c":
 		return Custom, nil
+	case Zstd.String(), "archive.zst", "zst", "z":
+		return Zstd, nil
 	}
 	return Unknown, fmt.Errorf("%w: %s", ErrUnknown, format)
 }
@@ -12,11 +12,12 @@ func _() {
 	_ = x[Gzip-1]
 	_ = x[Plain-2]
 	_ = x[Custom-3]
+	_ = x[Zstd-4]
 }
 
-const _Format_name = "unknowngzipplaincustom"
+const _Format_name = "unknowngzipplaincustomzstd"
 
-var _Format_index = [...]uint8{0, 7, 11, 16, 22}
+var _Format_index = [...]uint8{0, 7, 11, 16, 22, 26}
 
 func (i Format) String() string {
 	idx := int(i) - 0
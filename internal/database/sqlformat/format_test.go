@@ -25,6 +25,9 @@ func TestFormat_Set(t *testing.T) {
 		{"p", Format(0), args{"p"}, require.NoError},
 		{"custom", Format(0), args{"custom"}, require.NoError},
 		{"c", Format(0), args{"c"}, require.NoError},
+		{"zstd", Format(0), args{"zstd"}, require.NoError},
+		{"zst", Format(0), args{"zst"}, require.NoError},
+		{"z", Format(0), args{"z"}, require.NoError},
 		{"png", Format(0), args{"png"}, require.Error},
 	}
 	for _, tt := range tests {
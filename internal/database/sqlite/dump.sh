@@ -0,0 +1,24 @@
+#!/usr/bin/env sh
+set -eu
+: "${DB_PATH:?database path must be set with --dbname or the kubedb.clevyr.com/sqlite-path annotation}"
+
+if [ -z "${BACKUP:-}" ] && [ -z "${EXCLUDE_QUERY:-}" ]; then
+  exec sqlite3 -readonly "$DB_PATH" "$DUMP_COMMAND"
+fi
+
+# .backup writes a consistent copy of the database file, even while the app is writing
+tmp="$DB_PATH.kubedb-$$"
+trap 'rm -f "$tmp"' EXIT
+echo 'Creating backup' >&2
+sqlite3 "$DB_PATH" ".backup $(printf '%s' "$tmp" | sed 's/["\\]/\\&/g; s/.*/"&"/')"
+
+if [ -n "${EXCLUDE_QUERY:-}" ]; then
+  echo 'Removing excluded tables' >&2
+  sqlite3 -bail "$tmp" "$EXCLUDE_QUERY"
+fi
+
+if [ -n "${BACKUP:-}" ]; then
+  cat "$tmp"
+else
+  sqlite3 -readonly "$tmp" "$DUMP_COMMAND"
+fi
@@ -0,0 +1,29 @@
+#!/usr/bin/env sh
+set -eu
+: "${DB_PATH:?database path must be set with --dbname or the kubedb.clevyr.com/sqlite-path annotation}"
+
+# The restore is written next to the database, then renamed over it
+tmp="$DB_PATH.kubedb-$$"
+trap 'rm -f "$tmp"' EXIT
+rm -f "$tmp"
+
+echo 'Restoring to temporary file' >&2
+if [ -n "${BACKUP:-}" ]; then
+  cat >"$tmp"
+else
+  sqlite3 ${HALT_ON_ERROR:+-bail} "$tmp"
+fi
+
+echo 'Checking integrity' >&2
+result="$(sqlite3 "$tmp" 'PRAGMA integrity_check')"
+if [ "$result" != ok ]; then
+  printf '%s\n' "$result" >&2
+  exit 1
+fi
+
+# The app must be scaled down first. A running app keeps the old file open
+# and would not see the restore, and its journals are deleted below.
+echo 'Replacing database' >&2
+mv -f "$tmp" "$DB_PATH"
+# Journals from the previous database would corrupt the new one
+rm -f "$DB_PATH-wal" "$DB_PATH-shm" "$DB_PATH-journal"
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/kubernetes/filter"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	_ conftypes.DBAliaser     = SQLite{}
+	_ conftypes.DBDumper      = SQLite{}
+	_ conftypes.DBExecer      = SQLite{}
+	_ conftypes.DBRestorer    = SQLite{}
+	_ conftypes.DBHasDatabase = SQLite{}
+	_ conftypes.DBTableLister = SQLite{}
+	_ conftypes.DBJobVolumer  = SQLite{}
+)
+
+// PathAnnotation is set on app pods to the path of their SQLite database file.
+const PathAnnotation = "kubedb.clevyr.com/sqlite-path"
+
+type SQLite struct{}
+
+func (SQLite) Name() string { return "sqlite" }
+
+func (SQLite) PrettyName() string { return "SQLite" }
+
+func (SQLite) Aliases() []string { return []string{"sqlite3"} }
+
+func (SQLite) PodFilters() filter.Filter {
+	return filter.Annotation{Name: PathAnnotation}
+}
+
+// DatabaseEnvs finds the path to the database file.
+func (SQLite) DatabaseEnvs(_ *conftypes.Global) kubernetes.ConfigLookups {
+	return kubernetes.ConfigLookups{kubernetes.LookupAnnotation(PathAnnotation)}
+}
+
+func (SQLite) TableListQuery() string {
+	return "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+}
+
+var (
+	ErrNoPath  = errors.New("database path is not set")
+	ErrNoClaim = errors.New("database file is not on a persistent volume claim, try --create-job=false")
+)
+
+// JobVolumes mounts the claim which contains the database file, so the job
+// can read it at the same path as the app.
+func (SQLite) JobVolumes(conf *conftypes.Global, container corev1.Container) ([]corev1.Volume, []corev1.VolumeMount, error) {
+	if conf.Database == "" {
+		return nil, nil, ErrNoPath
+	}
+
+	var mount *corev1.VolumeMount
+	for i, m := range container.VolumeMounts {
+		dir := path.Clean(m.MountPath)
+		if strings.HasPrefix(conf.Database, strings.TrimSuffix(dir, "/")+"/") &&
+			(mount == nil || len(dir) > len(path.Clean(mount.MountPath))) {
+			mount = &container.VolumeMounts[i]
+		}
+	}
+	if mount != nil {
+		for _, volume := range conf.DBPod.Spec.Volumes {
+			if volume.Name == mount.Name && volume.PersistentVolumeClaim != nil {
+				return []corev1.Volume{volume}, []corev1.VolumeMount{*mount}, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrNoClaim, conf.Database)
+}
+
+func (SQLite) ExecCommand(conf *conftypes.Exec) *command.Builder {
+	cmd := command.NewBuilder("exec", "sqlite3")
+	if conf.DisableHeaders {
+		cmd.Push("-noheader", "-list")
+	}
+	cmd.Push(conf.Database)
+	if conf.Command != "" {
+		cmd.Push(conf.Command)
+	}
+	return cmd
+}
+
+//go:embed dump.sh
+var dumpScript string
+
+// DumpCommand writes SQL statements with .dump, or a copy of the database file
+// with .backup for the custom format.
+// Excluded tables are removed from a copy of the database before it is dumped.
+func (db SQLite) DumpCommand(conf *conftypes.Dump) *command.Builder {
+	cmd := command.NewBuilder(
+		command.NewEnv("DB_PATH", conf.Database),
+		"sh", "-c", dumpScript,
+	)
+	if query := db.excludeQuery(conf); query != "" {
+		cmd.Unshift(command.NewEnv("EXCLUDE_QUERY", query))
+	}
+	if conf.Format == sqlformat.Custom {
+		cmd.Unshift(command.NewEnv("BACKUP", "true"))
+	} else {
+		cmd.Unshift(command.NewEnv("DUMP_COMMAND", db.dumpCommand(conf)))
+	}
+	return cmd
+}
+
+// dumpCommand returns the .dump command, limited to the requested tables.
+func (db SQLite) dumpCommand(conf *conftypes.Dump) string {
+	cmd := ".dump"
+	for _, table := range conf.Table {
+		cmd += " " + db.quoteArg(table)
+	}
+	return cmd
+}
+
+// excludeQuery drops excluded tables and deletes rows from tables whose data is excluded.
+func (db SQLite) excludeQuery(conf *conftypes.Dump) string {
+	if len(conf.ExcludeTable) == 0 && len(conf.ExcludeTableData) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	for _, table := range conf.ExcludeTable {
+		buf.WriteString("DROP TABLE IF EXISTS " + db.quoteIdentifier(table) + "; ")
+	}
+	for _, table := range conf.ExcludeTableData {
+		if !slices.Contains(conf.ExcludeTable, table) {
+			buf.WriteString("DELETE FROM " + db.quoteIdentifier(table) + "; ")
+		}
+	}
+	if conf.Format == sqlformat.Custom {
+		// Reclaim the space, so excluded data is not left in free pages
+		buf.WriteString("VACUUM;")
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+//go:embed restore.sh
+var restoreScript string
+
+// RestoreCommand restores to a temporary file, then renames it over the database.
+func (SQLite) RestoreCommand(conf *conftypes.Restore, inputFormat sqlformat.Format) *command.Builder {
+	cmd := command.NewBuilder(
+		command.NewEnv("DB_PATH", conf.Database),
+		"sh", "-c", restoreScript,
+	)
+	if inputFormat == sqlformat.Custom {
+		cmd.Unshift(command.NewEnv("BACKUP", "true"))
+	}
+	if conf.HaltOnError {
+		cmd.Unshift(command.NewEnv("HALT_ON_ERROR", "true"))
+	}
+	return cmd
+}
+
+func (SQLite) Formats() map[sqlformat.Format]string {
+	return map[sqlformat.Format]string{
+		sqlformat.Plain:  ".sql",
+		sqlformat.Gzip:   ".sql.gz",
+		sqlformat.Zstd:   ".sql.zst",
+		sqlformat.Custom: ".sqlite",
+	}
+}
+
+func (SQLite) quoteIdentifier(param string) string {
+	return `"` + strings.ReplaceAll(param, `"`, `""`) + `"`
+}
+
+// quoteArg quotes an argument to a sqlite3 dot command.
+func (SQLite) quoteArg(param string) string {
+	param = strings.ReplaceAll(param, `\`, `\\`)
+	param = strings.ReplaceAll(param, `"`, `\"`)
+	return `"` + param + `"`
+}
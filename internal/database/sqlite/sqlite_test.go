@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSQLite_JobVolumes(t *testing.T) {
+	pod := corev1.Pod{Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+		{Name: "config", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		{Name: "data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "app-data"},
+		}},
+	}}}
+	container := corev1.Container{VolumeMounts: []corev1.VolumeMount{
+		{Name: "config", MountPath: "/app"},
+		{Name: "data", MountPath: "/app/data/", SubPath: "db"},
+	}}
+
+	tests := []struct {
+		name       string
+		database   string
+		wantMounts []corev1.VolumeMount
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{"claim", "/app/data/app.db", []corev1.VolumeMount{container.VolumeMounts[1]}, require.NoError},
+		{"nested", "/app/data/sub/app.db", []corev1.VolumeMount{container.VolumeMounts[1]}, require.NoError},
+		{"not a claim", "/app/app.db", nil, require.Error},
+		{"not mounted", "/data/app.db", nil, require.Error},
+		{"prefix", "/app/database.db", nil, require.Error},
+		{"no path", "", nil, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &conftypes.Global{DBPod: pod, Database: tt.database}
+			volumes, mounts, err := SQLite{}.JobVolumes(conf, container)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantMounts, mounts)
+			if tt.wantMounts != nil {
+				assert.Equal(t, []corev1.Volume{pod.Spec.Volumes[1]}, volumes)
+			}
+		})
+	}
+}
+
+func TestSQLite_ExecCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Exec
+		want *command.Builder
+	}{
+		{
+			"default",
+			&conftypes.Exec{Global: &conftypes.Global{Database: "/data/app.db"}},
+			command.NewBuilder("exec", "sqlite3", "/data/app.db"),
+		},
+		{
+			"query",
+			&conftypes.Exec{Global: &conftypes.Global{Database: "/data/app.db"}, DisableHeaders: true, Command: "SELECT 1"},
+			command.NewBuilder("exec", "sqlite3", "-noheader", "-list", "/data/app.db", "SELECT 1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SQLite{}.ExecCommand(tt.conf))
+		})
+	}
+}
+
+func TestSQLite_DumpCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conftypes.Dump
+		want *command.Builder
+	}{
+		{
+			"plain",
+			&conftypes.Dump{Global: &conftypes.Global{Database: "/data/app.db"}, Format: sqlformat.Gzip},
+			command.NewBuilder(
+				command.NewEnv("DUMP_COMMAND", ".dump"), command.NewEnv("DB_PATH", "/data/app.db"),
+				"sh", "-c", dumpScript,
+			),
+		},
+		{
+			"tables",
+			&conftypes.Dump{Global: &conftypes.Global{Database: "/data/app.db"}, Table: []string{"users", `a"b`}},
+			command.NewBuilder(
+				command.NewEnv("DUMP_COMMAND", `.dump "users" "a\"b"`), command.NewEnv("DB_PATH", "/data/app.db"),
+				"sh", "-c", dumpScript,
+			),
+		},
+		{
+			"exclude",
+			&conftypes.Dump{
+				Global:           &conftypes.Global{Database: "/data/app.db"},
+				ExcludeTable:     []string{"secrets"},
+				ExcludeTableData: []string{`log"s`, "secrets"},
+			},
+			command.NewBuilder(
+				command.NewEnv("DUMP_COMMAND", ".dump"),
+				command.NewEnv("EXCLUDE_QUERY", `DROP TABLE IF EXISTS "secrets"; DELETE FROM "log""s";`),
+				command.NewEnv("DB_PATH", "/data/app.db"),
+				"sh", "-c", dumpScript,
+			),
+		},
+		{
+			"custom exclude",
+			&conftypes.Dump{
+				Global:       &conftypes.Global{Database: "/data/app.db"},
+				Format:       sqlformat.Custom,
+				ExcludeTable: []string{"secrets"},
+			},
+			command.NewBuilder(
+				command.NewEnv("BACKUP", "true"),
+				command.NewEnv("EXCLUDE_QUERY", `DROP TABLE IF EXISTS "secrets"; VACUUM;`),
+				command.NewEnv("DB_PATH", "/data/app.db"),
+				"sh", "-c", dumpScript,
+			),
+		},
+		{
+			"custom",
+			&conftypes.Dump{Global: &conftypes.Global{Database: "/data/app.db"}, Format: sqlformat.Custom},
+			command.NewBuilder(
+				command.NewEnv("BACKUP", "true"), command.NewEnv("DB_PATH", "/data/app.db"),
+				"sh", "-c", dumpScript,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SQLite{}.DumpCommand(tt.conf))
+		})
+	}
+}
+
+func TestSQLite_RestoreCommand(t *testing.T) {
+	conf := &conftypes.Restore{Global: &conftypes.Global{Database: "/data/app.db"}, HaltOnError: true}
+	assert.Equal(t,
+		command.NewBuilder(
+			command.NewEnv("HALT_ON_ERROR", "true"), command.NewEnv("BACKUP", "true"),
+			command.NewEnv("DB_PATH", "/data/app.db"),
+			"sh", "-c", restoreScript,
+		),
+		SQLite{}.RestoreCommand(conf, sqlformat.Custom),
+	)
+}
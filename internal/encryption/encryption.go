@@ -0,0 +1,192 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+)
+
+const (
+	Ext = ".age"
+
+	header      = "age-encryption.org/v1\n"
+	armorHeader = armor.Header
+)
+
+var (
+	ErrPassphraseRecipients = errors.New("a passphrase cannot be combined with other recipients")
+	ErrNoIdentity           = errors.New("file is encrypted, but no identities or passphrase are configured")
+	ErrInvalidRecipient     = errors.New("invalid recipient")
+	ErrInvalidIdentity      = errors.New("invalid identity")
+)
+
+// Enabled returns true if dumps should be encrypted.
+func Enabled(recipients []string, passphrase string) bool {
+	return len(recipients) != 0 || passphrase != ""
+}
+
+// ParseRecipients parses age public keys, ssh public keys, or paths to recipient files.
+// A passphrase is used instead of recipients if set.
+func ParseRecipients(recipients []string, passphrase string) ([]age.Recipient, error) {
+	if passphrase != "" {
+		if len(recipients) != 0 {
+			return nil, ErrPassphraseRecipients
+		}
+
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	}
+
+	expanded, err := ExpandRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]age.Recipient, 0, len(expanded))
+	for _, v := range expanded {
+		r, err := parseRecipient(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRecipient, v, err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// ExpandRecipients replaces paths to recipient files with the recipients they contain.
+func ExpandRecipients(recipients []string) ([]string, error) {
+	result := make([]string, 0, len(recipients))
+	for _, v := range recipients {
+		if _, err := parseRecipient(v); err == nil {
+			result = append(result, v)
+			continue
+		}
+
+		b, err := os.ReadFile(expandPath(v))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRecipient, v, err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if _, err := parseRecipient(line); err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRecipient, v, err)
+			}
+			result = append(result, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func parseRecipient(s string) (age.Recipient, error) {
+	if strings.HasPrefix(s, "ssh-") {
+		return agessh.ParseRecipient(s)
+	}
+	return age.ParseX25519Recipient(s)
+}
+
+// ParseIdentities parses age secret keys or paths to age or ssh identity files.
+// A passphrase identity is also added if set.
+func ParseIdentities(identities []string, passphrase string) ([]age.Identity, error) {
+	result := make([]age.Identity, 0, len(identities)+1)
+	if passphrase != "" {
+		i, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, i)
+	}
+
+	for _, v := range identities {
+		if strings.HasPrefix(v, "AGE-SECRET-KEY-") {
+			i, err := age.ParseX25519Identity(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, i)
+			continue
+		}
+
+		b, err := os.ReadFile(expandPath(v))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidIdentity, v, err)
+		}
+
+		if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN")) {
+			i, err := agessh.ParseIdentity(b)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidIdentity, v, err)
+			}
+			result = append(result, i)
+			continue
+		}
+
+		parsed, err := age.ParseIdentities(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidIdentity, v, err)
+		}
+		result = append(result, parsed...)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoIdentity
+	}
+	return result, nil
+}
+
+func expandPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"+string(os.PathSeparator)); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return os.ExpandEnv(path)
+}
+
+// Encrypt returns a WriteCloser which encrypts to w. Close must be called to flush the final chunk.
+func Encrypt(w io.Writer, recipients ...age.Recipient) (io.WriteCloser, error) {
+	return age.Encrypt(w, recipients...)
+}
+
+// IsEncrypted checks whether r begins with an age header, without consuming it.
+func IsEncrypted(r *bufio.Reader) bool {
+	b, _ := r.Peek(len(armorHeader))
+	return bytes.HasPrefix(b, []byte(header)) || bytes.HasPrefix(b, []byte(armorHeader))
+}
+
+// Decrypt returns a Reader which decrypts r. Armored files are also supported.
+func Decrypt(r *bufio.Reader, identities ...age.Identity) (io.Reader, error) {
+	var src io.Reader = r
+	if b, _ := r.Peek(len(armorHeader)); bytes.HasPrefix(b, []byte(armorHeader)) {
+		src = armor.NewReader(r)
+	}
+	return age.Decrypt(src, identities...)
+}
+
+// Describe formats recipients for display.
+func Describe(recipients []string, passphrase string) []string {
+	if passphrase != "" {
+		return []string{"passphrase"}
+	}
+	return recipients
+}
@@ -0,0 +1,109 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, armored bool, recipients ...age.Recipient) []byte {
+	var buf bytes.Buffer
+	dst := io.WriteCloser(nopCloser{&buf})
+	if armored {
+		dst = armor.NewWriter(&buf)
+	}
+
+	w, err := Encrypt(dst, recipients...)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "SELECT 1;")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, dst.Close())
+	return buf.Bytes()
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestEncryptDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	identityFile := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(identityFile, []byte("# key\n"+identity.String()+"\n"), 0o600))
+
+	recipientFile := filepath.Join(t.TempDir(), "recipients.txt")
+	require.NoError(t, os.WriteFile(recipientFile, []byte(identity.Recipient().String()+"\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		recipients []string
+		identities []string
+		passphrase string
+		armored    bool
+	}{
+		{"key", []string{identity.Recipient().String()}, []string{identity.String()}, "", false},
+		{"files", []string{recipientFile}, []string{identityFile}, "", false},
+		{"armored", []string{identity.Recipient().String()}, []string{identityFile}, "", true},
+		{"passphrase", nil, nil, "hunter2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipients, err := ParseRecipients(tt.recipients, tt.passphrase)
+			require.NoError(t, err)
+			identities, err := ParseIdentities(tt.identities, tt.passphrase)
+			require.NoError(t, err)
+
+			r := bufio.NewReader(bytes.NewReader(encrypt(t, tt.armored, recipients...)))
+			require.True(t, IsEncrypted(r))
+
+			plain, err := Decrypt(r, identities...)
+			require.NoError(t, err)
+			got, err := io.ReadAll(plain)
+			require.NoError(t, err)
+			assert.Equal(t, "SELECT 1;", string(got))
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, IsEncrypted(bufio.NewReader(bytes.NewReader([]byte("SELECT 1;")))))
+	assert.False(t, IsEncrypted(bufio.NewReader(bytes.NewReader(nil))))
+}
+
+func TestParseRecipients(t *testing.T) {
+	_, err := ParseRecipients([]string{"age1invalid"}, "hunter2")
+	require.ErrorIs(t, err, ErrPassphraseRecipients)
+
+	_, err = ParseRecipients([]string{"not-a-key-or-file"}, "")
+	require.Error(t, err)
+}
+
+func TestExpandRecipients(t *testing.T) {
+	a, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	b, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "recipients.txt")
+	contents := "# team\n" + b.Recipient().String() + "\n\n"
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+
+	got, err := ExpandRecipients([]string{a.Recipient().String(), path})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.Recipient().String(), b.Recipient().String()}, got)
+}
+
+func TestParseIdentities(t *testing.T) {
+	_, err := ParseIdentities(nil, "")
+	require.ErrorIs(t, err, ErrNoIdentity)
+}
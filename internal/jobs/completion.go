@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"log/slog"
+
+	"github.com/clevyr/kubedb/internal/completion"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+// NewClient creates a Kubernetes client for the configured namespace.
+func NewClient() (kubernetes.KubeClient, error) {
+	return kubernetes.NewClient(config.Global.Kubeconfig, config.Global.Context, config.Global.Namespace)
+}
+
+// CompleteNames completes the names of kubedb jobs.
+func CompleteNames(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if err := completion.LoadConfig(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	client, err := NewClient()
+	if err != nil {
+		slog.Error("Failed to create Kubernetes client", "error", err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	list, err := List(cmd.Context(), client)
+	if err != nil {
+		slog.Error("Failed to list jobs", "error", err)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(list))
+	for _, job := range list {
+		names = append(names, job.Name+"\t"+job.Component+"; "+string(job.Status))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Selector matches the jobs created by kubedb.
+const Selector = "app.kubernetes.io/name=kubedb"
+
+type Status string
+
+const (
+	StatusPending   Status = "Pending"
+	StatusRunning   Status = "Running"
+	StatusSucceeded Status = "Succeeded"
+	StatusFailed    Status = "Failed"
+)
+
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrNoPod    = errors.New("job has no pod")
+)
+
+// Job is a kubedb job.
+type Job struct {
+	Name      string
+	Component string
+	Detached  bool
+	Status    Status
+	Outputs   []string
+	Started   time.Time
+	Finished  time.Time
+}
+
+func newJob(job batchv1.Job) Job {
+	j := Job{
+		Name:      job.Name,
+		Component: job.Labels["app.kubernetes.io/component"],
+		Detached:  job.Labels[util.DetachedLabel] == "true",
+		Status:    status(job),
+		Started:   job.CreationTimestamp.Time,
+	}
+	if out := job.Annotations[util.OutputAnnotation]; out != "" {
+		j.Outputs = strings.Split(out, ",")
+	}
+	if job.Status.CompletionTime != nil {
+		j.Finished = job.Status.CompletionTime.Time
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			j.Finished = cond.LastTransitionTime.Time
+		}
+	}
+	return j
+}
+
+func status(job batchv1.Job) Status {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return StatusSucceeded
+		case batchv1.JobFailed:
+			return StatusFailed
+		}
+	}
+	if job.Status.Active != 0 {
+		return StatusRunning
+	}
+	return StatusPending
+}
+
+// Done returns true if the job has finished.
+func (j Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// Duration returns how long the job ran.
+func (j Job) Duration(now time.Time) time.Duration {
+	if !j.Finished.IsZero() {
+		now = j.Finished
+	}
+	return now.Sub(j.Started).Truncate(time.Second)
+}
+
+// List returns the kubedb jobs in the namespace, newest first.
+func List(ctx context.Context, client kubernetes.KubeClient) ([]Job, error) {
+	list, err := client.Jobs().List(ctx, metav1.ListOptions{LabelSelector: Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Job, 0, len(list.Items))
+	for _, job := range list.Items {
+		result = append(result, newJob(job))
+	}
+	slices.SortStableFunc(result, func(a, b Job) int {
+		return cmp.Compare(b.Started.UnixNano(), a.Started.UnixNano())
+	})
+	return result, nil
+}
+
+// Get returns a kubedb job by name.
+func Get(ctx context.Context, client kubernetes.KubeClient, name string) (Job, error) {
+	job, err := client.Jobs().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Labels["app.kubernetes.io/name"] != "kubedb" {
+		return Job{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return newJob(*job), nil
+}
+
+// Pod returns the pod created by a job.
+func Pod(ctx context.Context, client kubernetes.KubeClient, name string) (corev1.Pod, error) {
+	key, val := util.JobPodNameLabel(client, name)
+	list, err := client.Pods().List(ctx, metav1.ListOptions{LabelSelector: key + "=" + val})
+	if err != nil {
+		return corev1.Pod{}, err
+	}
+	if len(list.Items) == 0 {
+		return corev1.Pod{}, fmt.Errorf("%w: %s", ErrNoPod, name)
+	}
+	return list.Items[0], nil
+}
+
+// Logs streams the logs of a container in the job's pod.
+func Logs(ctx context.Context, client kubernetes.KubeClient, pod, container string, follow bool) (io.ReadCloser, error) {
+	return client.Pods().GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    follow,
+	}).Stream(ctx)
+}
+
+// Cancel deletes a job, which stops its pod.
+func Cancel(ctx context.Context, client kubernetes.KubeClient, name string) error {
+	if _, err := Get(ctx, client, name); err != nil {
+		return err
+	}
+	background := metav1.DeletePropagationBackground
+	return client.Jobs().Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &background})
+}
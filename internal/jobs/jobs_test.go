@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_newJob(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	finished := started.Add(90 * time.Second)
+	condition := func(t batchv1.JobConditionType) []batchv1.JobCondition {
+		return []batchv1.JobCondition{
+			{Type: batchv1.JobSuspended, Status: corev1.ConditionFalse},
+			{Type: t, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(finished)},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status batchv1.JobStatus
+		want   Status
+		done   bool
+	}{
+		{"pending", batchv1.JobStatus{}, StatusPending, false},
+		{"running", batchv1.JobStatus{Active: 1}, StatusRunning, false},
+		{"succeeded", batchv1.JobStatus{Conditions: condition(batchv1.JobComplete), CompletionTime: new(metav1.NewTime(finished))}, StatusSucceeded, true},
+		{"failed", batchv1.JobStatus{Conditions: condition(batchv1.JobFailed)}, StatusFailed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "kubedb-dump-abc",
+					CreationTimestamp: metav1.NewTime(started),
+					Labels: map[string]string{
+						"app.kubernetes.io/component": "dump",
+						util.DetachedLabel:            "true",
+					},
+					Annotations: map[string]string{util.OutputAnnotation: "s3://a/1.sql,pvc://b/1.sql"},
+				},
+				Status: tt.status,
+			}
+
+			got := newJob(job)
+			assert.Equal(t, tt.want, got.Status)
+			assert.Equal(t, tt.done, got.Done())
+			assert.Equal(t, "dump", got.Component)
+			assert.True(t, got.Detached)
+			assert.Equal(t, []string{"s3://a/1.sql", "pvc://b/1.sql"}, got.Outputs)
+			if tt.done {
+				assert.Equal(t, 90*time.Second, got.Duration(finished.Add(time.Hour)))
+			} else {
+				assert.Equal(t, time.Minute, got.Duration(started.Add(time.Minute)))
+			}
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	const logs = `time=2024-01-02 level=INFO msg="Uploading"
+{"written":512}
+{"written":1024}
+{"result":{"size":1024,"outputs":[{"path":"s3://bucket/a.sql"}]}}
+`
+
+	var out strings.Builder
+	var events []*dump.ClusterUploadEvent
+	require.NoError(t, Follow(strings.NewReader(logs), &out, func(event *dump.ClusterUploadEvent) {
+		events = append(events, event)
+	}))
+
+	assert.Equal(t, "time=2024-01-02 level=INFO msg=\"Uploading\"\n", out.String())
+	require.Len(t, events, 3)
+	assert.Equal(t, int64(1024), events[1].Written)
+	require.NotNil(t, events[2].Result)
+	assert.Equal(t, "s3://bucket/a.sql", events[2].Result.Outputs[0].Path)
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/clevyr/kubedb/internal/actions/dump"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Progress is the latest state reported by a detached job's uploader.
+type Progress struct {
+	Written int64
+	Result  *dump.ClusterUploadResult
+}
+
+// Follow reads uploader output from r, calling fn for each event.
+// Lines which are not events are written to logs.
+func Follow(r io.Reader, logs io.Writer, fn func(event *dump.ClusterUploadEvent)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if event, ok := dump.ParseClusterUploadEvent(scanner.Bytes()); ok {
+			fn(event)
+			continue
+		}
+		if _, err := logs.Write(append(scanner.Bytes(), '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// GetProgress returns the latest progress from the tail of the uploader's logs.
+func GetProgress(ctx context.Context, client kubernetes.KubeClient, pod corev1.Pod) (Progress, error) {
+	var tail int64 = 20
+	stream, err := client.Pods().GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: util.UploaderContainer,
+		TailLines: &tail,
+	}).Stream(ctx)
+	if err != nil {
+		return Progress{}, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	var progress Progress
+	err = Follow(stream, io.Discard, func(event *dump.ClusterUploadEvent) {
+		progress.Written = max(progress.Written, event.Written)
+		if event.Result != nil {
+			progress.Result = event.Result
+		}
+	})
+	if err != nil {
+		slog.Debug("Failed to read progress", "pod", pod.Name, "error", err)
+	}
+	return progress, err
+}
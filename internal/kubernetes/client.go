@@ -33,6 +33,14 @@ func (client KubeClient) Secrets() v1.SecretInterface {
 	return client.ClientSet.CoreV1().Secrets(client.Namespace)
 }
 
+func (client KubeClient) PersistentVolumeClaims() v1.PersistentVolumeClaimInterface {
+	return client.ClientSet.CoreV1().PersistentVolumeClaims(client.Namespace)
+}
+
+func (client KubeClient) ServiceAccounts() v1.ServiceAccountInterface {
+	return client.ClientSet.CoreV1().ServiceAccounts(client.Namespace)
+}
+
 func (client KubeClient) Jobs() batchv1.JobInterface {
 	return client.ClientSet.BatchV1().Jobs(client.Namespace)
 }
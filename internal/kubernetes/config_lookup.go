@@ -182,3 +182,14 @@ type LookupDefault string
 func (l LookupDefault) GetValue(context.Context, KubeClient, corev1.Pod) (string, error) {
 	return string(l), nil
 }
+
+var ErrAnnotationNoExist = errors.New("annotation is not set")
+
+type LookupAnnotation string
+
+func (a LookupAnnotation) GetValue(_ context.Context, _ KubeClient, pod corev1.Pod) (string, error) {
+	if v := pod.Annotations[string(a)]; v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrAnnotationNoExist, string(a))
+}
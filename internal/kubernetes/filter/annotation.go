@@ -0,0 +1,12 @@
+package filter
+
+import corev1 "k8s.io/api/core/v1"
+
+// Annotation matches pods which set the annotation.
+type Annotation struct {
+	Name string
+}
+
+func (annotation Annotation) Matches(pod corev1.Pod) bool {
+	return pod.Annotations[annotation.Name] != ""
+}
@@ -0,0 +1,30 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnnotation_Matches(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{"key": "value", "empty": ""},
+	}}
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       bool
+	}{
+		{"set", "key", true},
+		{"empty", "empty", false},
+		{"missing", "other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Annotation{Name: tt.annotation}.Matches(pod))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package filter
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Image matches pods with a container running an image from one of the repositories.
+// Tags and digests are ignored.
+type Image []string
+
+func (repos Image) Matches(pod corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		repo, _, _ := strings.Cut(container.Image, "@")
+		if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+			repo = repo[:i]
+		}
+		if slices.Contains(repos, repo) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImage_Matches(t *testing.T) {
+	pod := func(image string) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Image: "busybox"},
+			{Image: image},
+		}}}
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  bool
+	}{
+		{"tag", "mcr.microsoft.com/mssql/server:2022-latest", true},
+		{"digest", "mcr.microsoft.com/mssql/server@sha256:abc", true},
+		{"tag and digest", "mcr.microsoft.com/mssql/server:2022-latest@sha256:abc", true},
+		{"no tag", "mcr.microsoft.com/mssql/server", true},
+		{"other image", "mcr.microsoft.com/mssql/server-tools:latest", false},
+		{"registry port", "localhost:5000/server", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Image{"mcr.microsoft.com/mssql/server"}.Matches(pod(tt.image)))
+		})
+	}
+}
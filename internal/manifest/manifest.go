@@ -0,0 +1,136 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/storage"
+)
+
+const Ext = ".json"
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Manifest describes a dump. It is written next to the dump as "<file>.json".
+type Manifest struct {
+	SHA256    string           `json:"sha256"`
+	Size      int64            `json:"size"`
+	Dialect   string           `json:"dialect"`
+	Format    sqlformat.Format `json:"format"`
+	Namespace string           `json:"namespace"`
+	Pod       string           `json:"pod"`
+	Database  string           `json:"database,omitempty"`
+	Parts     int              `json:"parts,omitempty"`
+}
+
+// Path returns the manifest path for a dump. URL query params are preserved.
+func Path(path string) string {
+	if storage.IsCloud(path) || storage.IsHTTP(path) {
+		if u, err := url.Parse(path); err == nil {
+			u.Path += Ext
+			return u.String()
+		}
+	}
+	return path + Ext
+}
+
+// Write saves the manifest next to the dump at path.
+func (m *Manifest) Write(ctx context.Context, client storage.Client, path string, opts storage.PutOptions) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if client != nil {
+		return client.PutObject(ctx, bytes.NewReader(b), Path(path), opts)
+	}
+	return os.WriteFile(Path(path), b, 0o644)
+}
+
+// Read loads the manifest for the dump at path.
+func Read(ctx context.Context, client storage.Client, path string) (*Manifest, error) {
+	var r io.ReadCloser
+	if client != nil {
+		var err error
+		if r, err = client.GetObject(ctx, Path(path)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if r, err = os.Open(Path(path)); err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Digest computes the checksum and size of everything written to it.
+type Digest struct {
+	hash hash.Hash
+	size int64
+}
+
+func NewDigest() *Digest {
+	return &Digest{hash: sha256.New()}
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	d.size += int64(len(p))
+	return d.hash.Write(p)
+}
+
+func (d *Digest) Sum() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+func (d *Digest) Size() int64 {
+	return d.size
+}
+
+// NewVerifier returns a Reader which returns ErrChecksumMismatch instead of io.EOF
+// if the data read does not match the manifest.
+func (m *Manifest) NewVerifier(r io.Reader) io.Reader {
+	return &verifier{r: r, manifest: m, digest: NewDigest()}
+}
+
+type verifier struct {
+	r        io.Reader
+	manifest *Manifest
+	digest   *Digest
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	_, _ = v.digest.Write(p[:n])
+	if v.digest.Size() > v.manifest.Size {
+		return n, fmt.Errorf("%w: expected %d bytes, got more", ErrChecksumMismatch, v.manifest.Size)
+	}
+	if errors.Is(err, io.EOF) {
+		switch {
+		case v.digest.Size() != v.manifest.Size:
+			return n, fmt.Errorf("%w: expected %d bytes, got %d", ErrChecksumMismatch, v.manifest.Size, v.digest.Size())
+		case v.digest.Sum() != v.manifest.SHA256:
+			return n, fmt.Errorf("%w: expected sha256 %s, got %s", ErrChecksumMismatch, v.manifest.SHA256, v.digest.Sum())
+		}
+	}
+	return n, err
+}
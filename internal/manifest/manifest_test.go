@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/database/sqlformat"
+	"github.com/clevyr/kubedb/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"local", "test.sql.gz", "test.sql.gz.json"},
+		{"s3", "s3://bucket/test.sql.gz", "s3://bucket/test.sql.gz.json"},
+		{"presigned", "https://example.com/test.sql.gz?X-Amz-Signature=a", "https://example.com/test.sql.gz.json?X-Amz-Signature=a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Path(tt.path))
+		})
+	}
+}
+
+func TestManifest_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sql.gz")
+
+	digest := NewDigest()
+	_, err := io.WriteString(digest, "SELECT 1;")
+	require.NoError(t, err)
+
+	want := &Manifest{
+		SHA256:    digest.Sum(),
+		Size:      digest.Size(),
+		Dialect:   "postgres",
+		Format:    sqlformat.Gzip,
+		Namespace: "test",
+		Pod:       "postgres-0",
+	}
+	require.NoError(t, want.Write(t.Context(), nil, path, storage.PutOptions{}))
+
+	got, err := Read(t.Context(), nil, path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestManifest_NewVerifier(t *testing.T) {
+	digest := NewDigest()
+	_, err := io.WriteString(digest, "SELECT 1;")
+	require.NoError(t, err)
+	m := &Manifest{SHA256: digest.Sum(), Size: digest.Size()}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"valid", "SELECT 1;", require.NoError},
+		{"truncated", "SELECT 1", require.Error},
+		{"too long", "SELECT 1;;", require.Error},
+		{"modified", "SELECT 2;", require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := io.ReadAll(m.NewVerifier(strings.NewReader(tt.input)))
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, ErrChecksumMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/util"
+	"golang.org/x/time/rate"
+)
+
+var ErrInvalidRate = errors.New("invalid rate")
+
+// Parse converts a rate like "20MiB/s" or "500k" into bytes per second.
+// An empty string disables the limit.
+func Parse(s string) (int64, error) {
+	v, err := util.ParseSize(strings.TrimSuffix(strings.TrimSpace(s), "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidRate, s)
+	}
+	return v, nil
+}
+
+// ForNamespace returns the rate for the first namespace regex which matches, or def if none match.
+func ForNamespace(namespaces map[string]string, namespace, def string) (string, error) {
+	for k, v := range namespaces {
+		re, err := regexp.Compile(k)
+		if err != nil {
+			return "", err
+		}
+		if re.MatchString(namespace) {
+			return v, nil
+		}
+	}
+	return def, nil
+}
+
+// NewLimiter returns a limiter for the given bytes per second, or nil if limit is not positive.
+func NewLimiter(limit int64) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	// Allow up to 1/10 of a second of data per chunk to keep the rate smooth
+	burst := int(max(min(limit/10, 1<<20), 1))
+	return rate.NewLimiter(rate.Limit(limit), burst)
+}
+
+type reader struct {
+	ctx     context.Context //nolint:containedctx
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+// NewReader throttles reads from r. If limiter is nil, r is returned unchanged.
+func NewReader(ctx context.Context, r io.Reader, limiter *rate.Limiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, limiter: limiter}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if err := r.limiter.WaitN(r.ctx, n); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+type writer struct {
+	ctx     context.Context //nolint:containedctx
+	w       io.Writer
+	limiter *rate.Limiter
+}
+
+// NewWriter throttles writes to w. If limiter is nil, w is returned unchanged.
+func NewWriter(ctx context.Context, w io.Writer, limiter *rate.Limiter) io.Writer {
+	if limiter == nil {
+		return w
+	}
+	return &writer{ctx: ctx, w: w, limiter: limiter}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	var written int
+	for len(p) != 0 {
+		chunk := p[:min(len(p), w.limiter.Burst())]
+		if err := w.limiter.WaitN(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"empty", "", 0, require.NoError},
+		{"zero", "0", 0, require.NoError},
+		{"per second", "20MiB/s", 20 << 20, require.NoError},
+		{"no suffix", "500k", 500 << 10, require.NoError},
+		{"unknown unit", "5 parsecs/s", 0, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, ErrInvalidRate)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestForNamespace(t *testing.T) {
+	namespaces := map[string]string{"^prod": "1MiB"}
+
+	got, err := ForNamespace(namespaces, "production", "10MiB")
+	require.NoError(t, err)
+	assert.Equal(t, "1MiB", got)
+
+	got, err = ForNamespace(namespaces, "staging", "10MiB")
+	require.NoError(t, err)
+	assert.Equal(t, "10MiB", got)
+
+	_, err = ForNamespace(map[string]string{"(": "1MiB"}, "prod", "")
+	require.Error(t, err)
+}
+
+func TestNewLimiter(t *testing.T) {
+	assert.Nil(t, NewLimiter(0))
+	assert.Equal(t, 100, NewLimiter(1000).Burst())
+	assert.Equal(t, 1<<20, NewLimiter(100<<20).Burst())
+	assert.Equal(t, 1, NewLimiter(5).Burst())
+}
+
+func TestReaderWriter(t *testing.T) {
+	const size = 3000
+	input := strings.Repeat("a", size)
+
+	t.Run("reader", func(t *testing.T) {
+		start := time.Now()
+		got, err := io.ReadAll(NewReader(t.Context(), strings.NewReader(input), NewLimiter(10000)))
+		require.NoError(t, err)
+		assert.Equal(t, input, string(got))
+		// The initial burst is free, then the rest waits at 10KB/s
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		start := time.Now()
+		n, err := io.WriteString(NewWriter(t.Context(), &buf, NewLimiter(10000)), input)
+		require.NoError(t, err)
+		assert.Equal(t, size, n)
+		assert.Equal(t, input, buf.String())
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r := strings.NewReader(input)
+		assert.Same(t, r, NewReader(t.Context(), r, nil))
+		var buf bytes.Buffer
+		assert.Same(t, &buf, NewWriter(t.Context(), &buf, nil))
+	})
+}
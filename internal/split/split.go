@@ -0,0 +1,182 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrMissingPart = errors.New("missing split part")
+
+var partRe = regexp.MustCompile(`\.part(\d{3,})$`)
+
+// Ext returns the extension appended to the nth volume. Volumes are numbered from 1.
+func Ext(n int) string {
+	return fmt.Sprintf(".part%03d", n)
+}
+
+// Path returns the path of the nth volume. URL query params are preserved.
+func Path(path string, n int) string {
+	if strings.Contains(path, "://") {
+		if u, err := url.Parse(path); err == nil {
+			u.Path += Ext(n)
+			return u.String()
+		}
+	}
+	return path + Ext(n)
+}
+
+// Trim removes a volume extension from path. If path is not a volume, ok is false.
+func Trim(path string) (base string, n int, ok bool) {
+	matches := partRe.FindStringSubmatchIndex(path)
+	if matches == nil {
+		return path, 0, false
+	}
+	n, err := strconv.Atoi(path[matches[2]:matches[3]])
+	if err != nil {
+		return path, 0, false
+	}
+	return path[:matches[0]], n, true
+}
+
+// Check verifies that numbers contains every volume from 1 to the highest number.
+// If want is positive, it is the expected number of volumes.
+func Check(base string, numbers []int, want int) error {
+	seen := make(map[int]bool, len(numbers))
+	last := max(want, 1)
+	for _, n := range numbers {
+		seen[n] = true
+		last = max(last, n)
+	}
+	for n := 1; n <= last; n++ {
+		if !seen[n] {
+			return fmt.Errorf("%w: %s", ErrMissingPart, base+Ext(n))
+		}
+	}
+	return nil
+}
+
+// Writer splits a stream into volumes of a fixed size.
+// Open is called with the volume number when a new volume is needed,
+// and each volume is closed once it is full.
+type Writer struct {
+	Size int64
+	Open func(n int) (io.WriteCloser, error)
+
+	cur     io.WriteCloser
+	n       int
+	written int64
+}
+
+func NewWriter(size int64, open func(n int) (io.WriteCloser, error)) *Writer {
+	return &Writer{Size: size, Open: open}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	var total int
+	for len(p) != 0 {
+		if w.cur == nil || w.written >= w.Size {
+			if err := w.next(); err != nil {
+				return total, err
+			}
+		}
+
+		chunk := p[:min(int64(len(p)), w.Size-w.written)]
+		n, err := w.cur.Write(chunk)
+		total += n
+		w.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
+
+func (w *Writer) next() error {
+	if w.cur != nil {
+		if err := w.cur.Close(); err != nil {
+			return err
+		}
+		w.cur = nil
+	}
+
+	w.n++
+	cur, err := w.Open(w.n)
+	if err != nil {
+		return err
+	}
+	w.cur = cur
+	w.written = 0
+	return nil
+}
+
+// Parts returns the number of volumes which have been opened.
+func (w *Writer) Parts() int {
+	return w.n
+}
+
+// Close closes the final volume. An empty volume is created if nothing was written.
+func (w *Writer) Close() error {
+	if w.cur == nil {
+		if err := w.next(); err != nil {
+			return err
+		}
+	}
+	err := w.cur.Close()
+	w.cur = nil
+	return err
+}
+
+// NewReader concatenates volumes 1 through parts, opening each one only once the previous volume is consumed.
+func NewReader(parts int, open func(n int) (io.ReadCloser, error)) io.ReadCloser {
+	return &reader{parts: parts, open: open}
+}
+
+type reader struct {
+	parts int
+	open  func(n int) (io.ReadCloser, error)
+
+	cur io.ReadCloser
+	n   int
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if r.n >= r.parts {
+				return 0, io.EOF
+			}
+			r.n++
+			cur, err := r.open(r.n)
+			if err != nil {
+				return 0, err
+			}
+			r.cur = cur
+		}
+
+		n, err := r.cur.Read(p)
+		if errors.Is(err, io.EOF) {
+			_ = r.cur.Close()
+			r.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *reader) Close() error {
+	if r.cur != nil {
+		err := r.cur.Close()
+		r.cur = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package split
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type buffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *buffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		wantBase string
+		wantN    int
+		wantOk   bool
+	}{
+		{"volume", "test_2024-03-05_130405.sql.gz.part001", "test_2024-03-05_130405.sql.gz", 1, true},
+		{"large volume", "a.sql.part1234", "a.sql", 1234, true},
+		{"not a volume", "a.sql.gz", "a.sql.gz", 0, false},
+		{"too short", "a.sql.part1", "a.sql.part1", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, n, ok := Trim(tt.path)
+			assert.Equal(t, tt.wantBase, base)
+			assert.Equal(t, tt.wantN, n)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	require.NoError(t, Check("a", []int{2, 1, 3}, 0))
+	require.NoError(t, Check("a", []int{1, 2, 3}, 3))
+	require.ErrorIs(t, Check("a", []int{1, 3}, 0), ErrMissingPart)
+	require.ErrorIs(t, Check("a", []int{1, 2}, 3), ErrMissingPart)
+	require.ErrorIs(t, Check("a", nil, 0), ErrMissingPart)
+}
+
+func TestWriterReader(t *testing.T) {
+	input := strings.Repeat("0123456789", 5)
+
+	var volumes []*buffer
+	w := NewWriter(16, func(n int) (io.WriteCloser, error) {
+		assert.Equal(t, len(volumes)+1, n)
+		volumes = append(volumes, &buffer{})
+		return volumes[n-1], nil
+	})
+	// Write in uneven chunks to cross volume boundaries
+	for _, chunk := range []string{input[:7], input[7:30], input[30:]} {
+		_, err := io.WriteString(w, chunk)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	require.Len(t, volumes, 4)
+	assert.Equal(t, 4, w.Parts())
+	for i, v := range volumes {
+		assert.True(t, v.closed)
+		if i < 3 {
+			assert.Equal(t, 16, v.Len())
+		}
+	}
+	assert.Equal(t, 2, volumes[3].Len())
+
+	r := NewReader(len(volumes), func(n int) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(volumes[n-1].Bytes())), nil
+	})
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, input, string(got))
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var volumes int
+	w := NewWriter(16, func(int) (io.WriteCloser, error) {
+		volumes++
+		return &buffer{}, nil
+	})
+	require.NoError(t, w.Close())
+	assert.Equal(t, 1, volumes)
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "dir/a.sql.part002", Path("dir/a.sql", 2))
+	assert.Equal(t, "s3://bucket/a.sql.part001?x=1", Path("s3://bucket/a.sql?x=1", 1))
+}
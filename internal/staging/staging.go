@@ -0,0 +1,338 @@
+package staging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gabe565.com/utils/bytefmt"
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ChunkSize is the amount of data sent by each upload exec.
+	ChunkSize = 16 * 1024 * 1024
+	// Retries is the number of times a transfer is resumed without making progress.
+	Retries = 5
+)
+
+var (
+	// RetryDelay is multiplied by the attempt number between retries.
+	RetryDelay = time.Second //nolint:gochecknoglobals
+	// PollInterval is the delay between checks for a background command.
+	PollInterval = 2 * time.Second //nolint:gochecknoglobals
+)
+
+var (
+	ErrVerify        = errors.New("staged file verification failed")
+	ErrCommandFailed = errors.New("staged command failed")
+)
+
+// Info describes the contents of a staged file.
+type Info struct {
+	Size   int64
+	SHA256 string
+}
+
+// File is a file in the job pod which is copied over exec.
+// Transfers resume from the last confirmed offset when the connection drops.
+type File struct {
+	pod       corev1.Pod
+	container string
+	path      string
+	logs      io.Writer
+	exec      func(ctx context.Context, opts kubernetes.ExecOptions) error
+}
+
+// New returns a file at path in a container of the job pod. Remote errors are written to logs.
+func New(client kubernetes.KubeClient, pod corev1.Pod, container, path string, logs io.Writer) *File {
+	return &File{
+		pod:       pod,
+		container: container,
+		path:      path,
+		logs:      logs,
+		exec:      client.Exec,
+	}
+}
+
+func (f *File) run(ctx context.Context, cmd *command.Builder, stdin io.Reader, stdout io.Writer) error {
+	return f.exec(ctx, kubernetes.ExecOptions{
+		Pod:         f.pod,
+		Container:   f.container,
+		Cmd:         cmd.String(),
+		Stdin:       stdin,
+		Stdout:      stdout,
+		Stderr:      f.logs,
+		DisablePing: true,
+	})
+}
+
+// createScript runs the command in $1 in the background, with its output written to the file in $2.
+// The log file marks that the command was started, so the script can be retried after a dropped connection.
+// The exit code is written once the command finishes.
+const createScript = `if [ ! -e "$2.log" ]; then
+  : >"$2.log"
+  nohup sh -c 'sh -c "$1" >"$2" 2>>"$2.log"; echo "$?" >"$2.exit.tmp" && mv "$2.exit.tmp" "$2.exit"' \
+    sh "$1" "$2" </dev/null >/dev/null 2>&1 &
+fi`
+
+// Create runs cmd in the pod with its output written to the file. The command is
+// detached from the connection, so it continues if the connection drops.
+// Its stderr is forwarded to the logs until it exits.
+func (f *File) Create(ctx context.Context, cmd *command.Builder) error {
+	start := command.NewBuilder("sh", "-c", createScript, "sh", cmd.String(), f.path)
+	if err := retry(ctx, func() (int64, error) {
+		return 0, f.run(ctx, start, nil, nil)
+	}); err != nil {
+		return err
+	}
+
+	logs := &countWriter{w: f.logs}
+	if logs.w == nil {
+		logs.w = io.Discard
+	}
+	var code string
+	err := retry(ctx, func() (int64, error) {
+		for {
+			var buf bytes.Buffer
+			status := command.NewBuilder("cat", f.path+".exit", command.Raw("2>/dev/null || true"))
+			if err := f.run(ctx, status, nil, &buf); err != nil {
+				return logs.n, err
+			}
+
+			// Forward the logs which were written since the last check
+			tail := command.NewBuilder("tail", "-c", "+"+strconv.FormatInt(logs.n+1, 10), f.path+".log")
+			if err := f.run(ctx, tail, nil, logs); err != nil {
+				return logs.n, err
+			}
+
+			if code = strings.TrimSpace(buf.String()); code != "" {
+				return logs.n, nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return logs.n, permanent{ctx.Err()}
+			case <-time.After(PollInterval):
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if code != "0" {
+		return fmt.Errorf("%w: exit code %s", ErrCommandFailed, code)
+	}
+	return nil
+}
+
+// Stat returns the size and checksum of the file.
+func (f *File) Stat(ctx context.Context) (Info, error) {
+	var buf bytes.Buffer
+	cmd := command.NewBuilder("wc", "-c", command.Raw("<"), f.path, command.Raw("&&"), "sha256sum", f.path)
+	if err := retry(ctx, func() (int64, error) {
+		buf.Reset()
+		return 0, f.run(ctx, cmd, nil, &buf)
+	}); err != nil {
+		return Info{}, err
+	}
+	return parseInfo(buf.String())
+}
+
+func parseInfo(s string) (Info, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return Info{}, fmt.Errorf("%w: unexpected output %q", ErrVerify, s)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return Info{}, fmt.Errorf("%w: %w", ErrVerify, err)
+	}
+	return Info{Size: size, SHA256: fields[1]}, nil
+}
+
+func (f *File) size(ctx context.Context) (int64, error) {
+	var buf bytes.Buffer
+	if err := f.run(ctx, command.NewBuilder("wc", "-c", command.Raw("<"), f.path), nil, &buf); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(buf.String()), 10, 64)
+}
+
+// Download writes the file to w, then verifies that it matches want.
+func (f *File) Download(ctx context.Context, w io.Writer, want Info) error {
+	hw := &hashWriter{w: w, hash: sha256.New()}
+	err := retry(ctx, func() (int64, error) {
+		offset := "+" + strconv.FormatInt(hw.n+1, 10)
+		err := f.run(ctx, command.NewBuilder("tail", "-c", offset, f.path), nil, hw)
+		if hw.err != nil {
+			// The local side failed, so resuming would not help
+			return hw.n, permanent{hw.err}
+		}
+		return hw.n, err
+	})
+	if err != nil {
+		return err
+	}
+	return verify(Info{Size: hw.n, SHA256: hex.EncodeToString(hw.hash.Sum(nil))}, want)
+}
+
+// Upload replaces the file with the contents of r, then verifies the result.
+// Data is sent in chunks, so only the current chunk is resent after a dropped connection.
+func (f *File) Upload(ctx context.Context, r io.Reader) (Info, error) {
+	if err := retry(ctx, func() (int64, error) {
+		return 0, f.run(ctx, command.NewBuilder(command.Raw(":"), command.Raw(">"), f.path), nil, nil)
+	}); err != nil {
+		return Info{}, err
+	}
+
+	h := sha256.New()
+	var size int64
+	buf := make([]byte, ChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n != 0 {
+			if err := f.appendChunk(ctx, size, buf[:n]); err != nil {
+				return Info{}, err
+			}
+			h.Write(buf[:n])
+			size += int64(n)
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		} else if readErr != nil {
+			return Info{}, readErr
+		}
+	}
+
+	got := Info{Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}
+	remote, err := f.Stat(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	return got, verify(remote, got)
+}
+
+// appendChunk appends chunk, which starts at offset, to the file.
+func (f *File) appendChunk(ctx context.Context, offset int64, chunk []byte) error {
+	var sent int64
+	var resume bool
+	return retry(ctx, func() (int64, error) {
+		if resume {
+			// Find out how much of the chunk arrived before the connection dropped
+			size, err := f.size(ctx)
+			if err != nil {
+				return offset + sent, err
+			}
+			if size < offset || size > offset+int64(len(chunk)) {
+				return offset + sent, permanent{fmt.Errorf("%w: remote size %d is outside of chunk at %d", ErrVerify, size, offset)}
+			}
+			sent = size - offset
+		}
+		resume = true
+
+		cmd := command.NewBuilder("cat", command.Raw(">>"), f.path)
+		err := f.run(ctx, cmd, bytes.NewReader(chunk[sent:]), nil)
+		return offset + sent, err
+	})
+}
+
+func verify(got, want Info) error {
+	switch {
+	case got.Size != want.Size:
+		return fmt.Errorf("%w: transferred %d of %d bytes", ErrVerify, got.Size, want.Size)
+	case got.SHA256 != want.SHA256:
+		return fmt.Errorf("%w: checksum mismatch", ErrVerify)
+	}
+	return nil
+}
+
+// permanent marks an error which should not be retried.
+type permanent struct {
+	error
+}
+
+func (p permanent) Unwrap() error {
+	return p.error
+}
+
+// retry calls fn until it succeeds. fn returns its current offset,
+// and the attempt count is reset whenever the offset advances.
+func retry(ctx context.Context, fn func() (int64, error)) error {
+	var attempts int
+	last := int64(-1)
+	for {
+		offset, err := fn()
+		if err == nil {
+			return nil
+		}
+		var perm permanent
+		if errors.As(err, &perm) {
+			return perm.error
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if offset != last {
+			attempts = 0
+			last = offset
+		}
+		attempts++
+		if attempts > Retries {
+			return err
+		}
+
+		slog.Warn("Lost connection to pod, resuming",
+			"offset", bytefmt.Encode(offset),
+			"attempt", attempts,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempts) * RetryDelay):
+		}
+	}
+}
+
+// hashWriter tracks the bytes which were accepted by w.
+type hashWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	n    int64
+	err  error
+}
+
+func (h *hashWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	h.hash.Write(p[:n])
+	h.n += int64(n)
+	if err != nil {
+		h.err = err
+	}
+	return n, err
+}
+
+// countWriter tracks the bytes which were accepted by w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,247 @@
+package staging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errLostConnection = errors.New("lost connection to pod")
+
+// fakePod simulates a file in the job pod. The first transfers drop the connection partway through.
+type fakePod struct {
+	data  []byte
+	drops int
+	calls []string
+}
+
+func (p *fakePod) exec(_ context.Context, opts kubernetes.ExecOptions) error {
+	p.calls = append(p.calls, opts.Cmd)
+	drop := p.drops > 0 && (strings.HasPrefix(opts.Cmd, "cat") || strings.HasPrefix(opts.Cmd, "tail"))
+	if drop {
+		p.drops--
+	}
+
+	switch {
+	case opts.Cmd == ": > /stage/file":
+		p.data = nil
+	case opts.Cmd == "cat >> /stage/file":
+		b, err := io.ReadAll(opts.Stdin)
+		if err != nil {
+			return err
+		}
+		if drop {
+			p.data = append(p.data, b[:len(b)/2]...)
+			return errLostConnection
+		}
+		p.data = append(p.data, b...)
+	case opts.Cmd == "wc -c < /stage/file":
+		_, err := fmt.Fprintf(opts.Stdout, "%8d\n", len(p.data))
+		return err
+	case strings.HasPrefix(opts.Cmd, "wc -c < /stage/file && sha256sum"):
+		sum := sha256.Sum256(p.data)
+		_, err := fmt.Fprintf(opts.Stdout, "%d\n%s  /stage/file\n", len(p.data), hex.EncodeToString(sum[:]))
+		return err
+	case strings.HasPrefix(opts.Cmd, "tail -c +"):
+		offset, err := strconv.Atoi(strings.Fields(opts.Cmd)[2][1:])
+		if err != nil {
+			return err
+		}
+		rest := p.data[offset-1:]
+		if drop {
+			_, _ = opts.Stdout.Write(rest[:len(rest)/2])
+			return errLostConnection
+		}
+		_, err = opts.Stdout.Write(rest)
+		return err
+	default:
+		return fmt.Errorf("unexpected command: %s", opts.Cmd) //nolint:err113
+	}
+	return nil
+}
+
+func (p *fakePod) file() *File {
+	return &File{path: "/stage/file", exec: p.exec}
+}
+
+func info(data []byte) Info {
+	sum := sha256.Sum256(data)
+	return Info{Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+func Test_parseInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Info
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"coreutils", "1024\nabc123  /kubedb-stage/dump\n", Info{Size: 1024, SHA256: "abc123"}, require.NoError},
+		{"busybox", "     1024\nabc123  /kubedb-stage/dump\n", Info{Size: 1024, SHA256: "abc123"}, require.NoError},
+		{"empty", "", Info{}, require.Error},
+		{"invalid size", "abc\nabc123  /kubedb-stage/dump\n", Info{}, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInfo(tt.s)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFile_Download(t *testing.T) {
+	RetryDelay = 0
+	data := []byte("SELECT 1;\nSELECT 2;\nSELECT 3;\nSELECT 4;\n")
+
+	t.Run("resumes", func(t *testing.T) {
+		pod := &fakePod{data: data, drops: 2}
+		var buf bytes.Buffer
+		require.NoError(t, pod.file().Download(t.Context(), &buf, info(data)))
+		assert.Equal(t, string(data), buf.String())
+		assert.Equal(t, []string{
+			"tail -c +1 /stage/file",
+			"tail -c +21 /stage/file",
+			"tail -c +31 /stage/file",
+		}, pod.calls)
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		f := &File{path: "/stage/file", exec: func(context.Context, kubernetes.ExecOptions) error {
+			return errLostConnection
+		}}
+		require.ErrorIs(t, f.Download(t.Context(), io.Discard, info(data)), errLostConnection)
+	})
+
+	t.Run("local error", func(t *testing.T) {
+		pod := &fakePod{data: data}
+		pr, pw := io.Pipe()
+		_ = pr.Close()
+		require.ErrorIs(t, pod.file().Download(t.Context(), pw, info(data)), io.ErrClosedPipe)
+		assert.Len(t, pod.calls, 1)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		pod := &fakePod{data: data}
+		require.ErrorIs(t, pod.file().Download(t.Context(), io.Discard, info(data[1:])), ErrVerify)
+	})
+}
+
+func TestFile_Upload(t *testing.T) {
+	RetryDelay = 0
+	data := bytes.Repeat([]byte("0123456789"), ChunkSize/5)
+
+	pod := &fakePod{data: []byte("stale"), drops: 2}
+	got, err := pod.file().Upload(t.Context(), bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, info(data), got)
+	assert.Equal(t, data, pod.data)
+
+	var appends int
+	for _, call := range pod.calls {
+		if call == "cat >> /stage/file" {
+			appends++
+		}
+	}
+	// The first chunk drops twice, then each chunk succeeds
+	assert.Equal(t, 4, appends)
+}
+
+// localExec runs commands in a local shell instead of the pod.
+func localExec(ctx context.Context, opts kubernetes.ExecOptions) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", opts.Cmd)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+// syncBuffer is written by both the stdout and stderr of local commands.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFile_Create(t *testing.T) {
+	RetryDelay = 0
+	PollInterval = 10 * time.Millisecond
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump")
+		var logs syncBuffer
+		f := &File{path: path, logs: &logs, exec: localExec}
+		cmd := command.NewBuilder(command.Raw("sleep 0.1; echo dumping >&2; echo 'SELECT 1;'"))
+		require.NoError(t, f.Create(t.Context(), cmd))
+
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1;\n", string(got))
+		assert.Equal(t, "dumping\n", logs.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		f := &File{path: filepath.Join(t.TempDir(), "dump"), exec: localExec}
+		cmd := command.NewBuilder(command.Raw("{ false || kill $$; } | cat"))
+		err := f.Create(t.Context(), cmd)
+		require.ErrorIs(t, err, ErrCommandFailed)
+		assert.Contains(t, err.Error(), "exit code 143")
+	})
+
+	t.Run("lost connection", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "dump")
+		var drops int
+		f := &File{path: path, exec: func(ctx context.Context, opts kubernetes.ExecOptions) error {
+			if err := localExec(ctx, opts); err != nil {
+				return err
+			}
+			if drops < 2 {
+				// The command ran, but the connection dropped before it returned
+				drops++
+				return errLostConnection
+			}
+			return nil
+		}}
+		runs := filepath.Join(dir, "runs")
+		cmd := command.NewBuilder("echo", "run", command.Raw(">>"), runs, command.Raw("&&"), "echo", "SELECT 1;")
+		require.NoError(t, f.Create(t.Context(), cmd))
+
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1;\n", string(got))
+
+		// The retried start does not run the command again
+		got, err = os.ReadFile(runs)
+		require.NoError(t, err)
+		assert.Equal(t, "run\n", string(got))
+	})
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const AzBlobSchema = "azblob://"
+
+func IsAzBlob(path string) bool {
+	return strings.HasPrefix(path, AzBlobSchema)
+}
+
+func IsAzBlobDir(path string) bool {
+	if !IsAzBlob(path) {
+		return false
+	}
+	if strings.HasSuffix(path, "/") {
+		return true
+	}
+	trimmed := strings.TrimPrefix(path, AzBlobSchema)
+	return !strings.Contains(trimmed, "/")
+}
+
+type AzBlob struct {
+	client     *azblob.Client
+	accessTier string
+}
+
+const (
+	azConnectionStringEnv = "AZURE_STORAGE_CONNECTION_STRING"
+	azAccountEnv          = "AZURE_STORAGE_ACCOUNT"
+	azKeyEnv              = "AZURE_STORAGE_KEY"
+	azEndpointEnv         = "AZURE_STORAGE_BLOB_ENDPOINT"
+)
+
+func NewAzBlob(remote conftypes.Remote) (*AzBlob, error) {
+	var account, key string
+	switch c := remote.Credentials; c.Source {
+	case conftypes.CredentialsDefault, conftypes.CredentialsEnv:
+		if connStr := os.Getenv(azConnectionStringEnv); connStr != "" {
+			client, err := azblob.NewClientFromConnectionString(connStr, nil)
+			if err != nil {
+				return nil, err
+			}
+			return &AzBlob{client: client, accessTier: remote.StorageClass}, nil
+		}
+		account = os.Getenv(azAccountEnv)
+		key = os.Getenv(azKeyEnv)
+	case conftypes.CredentialsStatic:
+		account, key = c.AccessKeyID, c.SecretAccessKey
+	default:
+		return nil, fmt.Errorf("%w: unsupported credentials source for azblob: %s", ErrInvalidRemote, c.Source)
+	}
+
+	if account == "" {
+		return nil, errors.NewUnauthorized(
+			"azblob unauthorized: please set " + azConnectionStringEnv + " or " + azAccountEnv,
+		)
+	}
+
+	serviceURL := remote.Endpoint
+	if serviceURL == "" {
+		serviceURL = os.Getenv(azEndpointEnv)
+	}
+	if serviceURL == "" {
+		serviceURL = "https://" + account + ".blob.core.windows.net/"
+	}
+
+	var client *azblob.Client
+	if key != "" {
+		cred, err := azblob.NewSharedKeyCredential(account, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil); err != nil {
+			return nil, err
+		}
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if client, err = azblob.NewClient(serviceURL, cred, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AzBlob{client: client, accessTier: remote.StorageClass}, nil
+}
+
+func (a *AzBlob) ListBuckets(ctx context.Context) iter.Seq2[*Bucket, error] {
+	return func(yield func(*Bucket, error) bool) {
+		pager := a.client.NewListContainersPager(nil)
+
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range page.ContainerItems {
+				if !yield(&Bucket{Name: *item.Name}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AzBlob) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		u, err := url.Parse(key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		u.Path = strings.TrimLeft(u.Path, "/")
+
+		pager := a.client.ServiceClient().NewContainerClient(u.Host).
+			NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{
+				Prefix: &u.Path,
+			})
+
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, prefix := range page.Segment.BlobPrefixes {
+				if !yield(&Object{
+					Name:  *prefix.Name,
+					IsDir: true,
+				}, nil) {
+					return
+				}
+			}
+
+			for _, item := range page.Segment.BlobItems {
+				myObj := &Object{Name: *item.Name}
+				if item.Properties != nil {
+					if item.Properties.LastModified != nil {
+						myObj.LastModified = *item.Properties.LastModified
+					}
+					if item.Properties.ContentLength != nil {
+						myObj.Size = *item.Properties.ContentLength
+					}
+				}
+
+				if !yield(myObj, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AzBlob) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	// UploadStream stages blocks as they are read, then commits the block list
+	uploadOpts := &azblob.UploadStreamOptions{Tags: opts.Tags}
+	if tier := cmp.Or(opts.StorageClass, a.accessTier); tier != "" {
+		uploadOpts.AccessTier = to.Ptr(blob.AccessTier(tier))
+	}
+	if opts.KMSKeyID != "" {
+		// Customer-managed keys are configured as encryption scopes
+		uploadOpts.CPKScopeInfo = &blob.CPKScopeInfo{EncryptionScope: &opts.KMSKeyID}
+	}
+	if len(opts.Metadata) != 0 {
+		uploadOpts.Metadata = make(map[string]*string, len(opts.Metadata))
+		for k, v := range opts.Metadata {
+			uploadOpts.Metadata[k] = to.Ptr(v)
+		}
+	}
+	_, err = a.client.UploadStream(ctx, u.Host, u.Path, r, uploadOpts)
+	return err
+}
+
+func (a *AzBlob) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	resp, err := a.client.DownloadStream(ctx, u.Host, u.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (a *AzBlob) DeleteObject(ctx context.Context, key string) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	_, err = a.client.DeleteBlob(ctx, u.Host, u.Path, nil)
+	return err
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAzBlob(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"relative local", args{"test.sql"}, false},
+		{"absolute local", args{"/home/test/test.sql"}, false},
+		{"azblob container", args{"azblob://test"}, true},
+		{"azblob container file", args{"azblob://test/test.sql"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsAzBlob(tt.args.path))
+		})
+	}
+}
+
+func TestIsAzBlobDir(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"relative local", args{"test.sql"}, false},
+		{"absolute local", args{"/home/test/test.sql"}, false},
+		{"azblob container", args{"azblob://test"}, true},
+		{"azblob container file", args{"azblob://test/test.sql"}, false},
+		{"azblob container dir", args{"azblob://test/subdir/"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsAzBlobDir(tt.args.path))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"iter"
 	"net/url"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/Backblaze/blazer/b2"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -38,9 +40,17 @@ const (
 	b2KeyEnv   = "B2_APPLICATION_KEY"
 )
 
-func NewB2(ctx context.Context) (*B2, error) {
-	id := os.Getenv(b2KeyIDEnv)
-	key := os.Getenv(b2KeyEnv)
+func NewB2(ctx context.Context, remote conftypes.Remote) (*B2, error) {
+	var id, key string
+	switch c := remote.Credentials; c.Source {
+	case conftypes.CredentialsDefault, conftypes.CredentialsEnv:
+		id = os.Getenv(b2KeyIDEnv)
+		key = os.Getenv(b2KeyEnv)
+	case conftypes.CredentialsStatic:
+		id, key = c.AccessKeyID, c.SecretAccessKey
+	default:
+		return nil, fmt.Errorf("%w: unsupported credentials source for b2: %s", ErrInvalidRemote, c.Source)
+	}
 	if id == "" || key == "" {
 		return nil, errors.NewUnauthorized("b2 unauthorized: please set " + b2KeyIDEnv + " and " + b2KeyEnv)
 	}
@@ -113,7 +123,11 @@ func (b *B2) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, err
 	}
 }
 
-func (b *B2) PutObject(ctx context.Context, r io.Reader, key string) error {
+func (b *B2) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
+	if opts.Encrypted() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedSSE, key)
+	}
+
 	u, err := url.Parse(key)
 	if err != nil {
 		return err
@@ -127,7 +141,7 @@ func (b *B2) PutObject(ctx context.Context, r io.Reader, key string) error {
 
 	obj := bucket.Object(u.Path)
 
-	w := obj.NewWriter(ctx)
+	w := obj.NewWriter(ctx, b2.WithAttrsOption(&b2.Attrs{Info: opts.Metadata}))
 	defer func() {
 		_ = w.Close()
 	}()
@@ -157,3 +171,18 @@ func (b *B2) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
 	r := obj.NewReader(ctx)
 	return r, nil
 }
+
+func (b *B2) DeleteObject(ctx context.Context, key string) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	bucket, err := b.client.Bucket(ctx, u.Host)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Object(u.Path).Delete(ctx)
+}
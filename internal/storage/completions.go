@@ -14,6 +14,28 @@ import (
 func CompleteBuckets(u *url.URL) ([]string, cobra.ShellCompDirective) {
 	u.Path = "/"
 
+	if IsRemote(u.String()) {
+		names := RemoteNames()
+		for i, name := range names {
+			u.Host = name
+			names[i] = u.String()
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+
+	if IsPVC(u.String()) {
+		names, err := PVCNames(context.Background())
+		if err != nil {
+			slog.Error("Failed to list claims", "error", err)
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for i, name := range names {
+			u.Host = name
+			names[i] = u.String()
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+
 	client, err := NewClient(context.Background(), u.String())
 	if err != nil {
 		slog.Error("Failed to create storage client", "error", err)
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"iter"
 	"net/url"
@@ -10,6 +12,7 @@ import (
 	"strings"
 
 	"cloud.google.com/go/storage"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -32,12 +35,25 @@ func IsGCSDir(path string) bool {
 }
 
 type GCS struct {
-	client    *storage.Client
-	projectID string
+	client       *storage.Client
+	projectID    string
+	storageClass string
 }
 
-func NewGCS(ctx context.Context, scope, projectID string) (*GCS, error) {
-	client, err := storage.NewClient(ctx, option.WithScopes(scope))
+func NewGCS(ctx context.Context, scope, projectID string, remote conftypes.Remote) (*GCS, error) {
+	opts := []option.ClientOption{option.WithScopes(scope)}
+	switch c := remote.Credentials; c.Source {
+	case conftypes.CredentialsDefault:
+	case conftypes.CredentialsFile:
+		opts = append(opts, option.WithCredentialsFile(c.File))
+	default:
+		return nil, fmt.Errorf("%w: unsupported credentials source for gcs: %s", ErrInvalidRemote, c.Source)
+	}
+	if remote.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(remote.Endpoint))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +69,9 @@ func NewGCS(ctx context.Context, scope, projectID string) (*GCS, error) {
 	}
 
 	return &GCS{
-		client:    client,
-		projectID: projectID,
+		client:       client,
+		projectID:    projectID,
+		storageClass: remote.StorageClass,
 	}, nil
 }
 
@@ -116,7 +133,7 @@ func (g *GCS) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, er
 	}
 }
 
-func (g *GCS) PutObject(ctx context.Context, r io.Reader, key string) error {
+func (g *GCS) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
 	u, err := url.Parse(key)
 	if err != nil {
 		return err
@@ -124,8 +141,15 @@ func (g *GCS) PutObject(ctx context.Context, r io.Reader, key string) error {
 	u.Path = strings.TrimLeft(u.Path, "/")
 
 	w := g.client.Bucket(u.Host).Object(u.Path).NewWriter(ctx)
-	_, err = io.Copy(w, r)
-	return err
+	w.StorageClass = cmp.Or(opts.StorageClass, g.storageClass)
+	// Objects are always encrypted, so SSE only changes the key when CMEK is used
+	w.KMSKeyName = opts.KMSKeyID
+	w.Metadata = opts.Metadata
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
 }
 
 func (g *GCS) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
@@ -141,3 +165,13 @@ func (g *GCS) GetObject(ctx context.Context, key string) (io.ReadCloser, error)
 	}
 	return r, nil
 }
+
+func (g *GCS) DeleteObject(ctx context.Context, key string) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	return g.client.Bucket(u.Host).Object(u.Path).Delete(ctx)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsHTTP(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"relative local", args{"test.sql"}, false},
+		{"absolute local", args{"/home/test/test.sql"}, false},
+		{"http", args{"http://example.com/test.sql"}, true},
+		{"https", args{"https://example.com/test.sql"}, true},
+		{"s3 bucket file", args{"s3://test/test.sql"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsHTTP(tt.args.path))
+		})
+	}
+}
+
+func TestHTTP_GetObject(t *testing.T) {
+	HTTPRetryDelay = 0
+	const content = "hello world"
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", `"abc"`)
+
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			assert.Equal(t, `"abc"`, r.Header.Get("If-Range"))
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+			require.NoError(t, err)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-offset))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = io.WriteString(w, content[offset:])
+			return
+		}
+
+		// Simulate a dropped connection halfway through the first response
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		_, _ = io.WriteString(w, content[:5])
+		w.(http.Flusher).Flush()
+		if hj, ok := w.(http.Hijacker); ok {
+			conn, _, _ := hj.Hijack()
+			_ = conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewHTTP([]conftypes.HTTPSource{{
+		Host:    strings.TrimPrefix(server.URL, "http://"),
+		Headers: map[string]string{"Authorization": "Bearer test"},
+	}})
+
+	r, err := client.GetObject(t.Context(), server.URL+"/test.sql")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = r.Close()
+	})
+
+	sized, ok := r.(interface{ Size() int64 })
+	require.True(t, ok)
+	assert.EqualValues(t, len(content), sized.Size())
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(got))
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,462 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/command"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/finalizer"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/clevyr/kubedb/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const PVCSchema = "pvc://"
+
+func IsPVC(path string) bool {
+	return strings.HasPrefix(path, PVCSchema)
+}
+
+func IsPVCDir(path string) bool {
+	if !IsPVC(path) {
+		return false
+	}
+	if strings.HasSuffix(path, "/") {
+		return true
+	}
+	trimmed := strings.TrimPrefix(path, PVCSchema)
+	return !strings.Contains(trimmed, "/")
+}
+
+var (
+	ErrInvalidPVC       = errors.New("invalid pvc path")
+	ErrPVCClaimMismatch = errors.New("path is on a different claim")
+	ErrPVCPodFailed     = errors.New("pvc helper pod failed")
+)
+
+// PVCClaim returns the claim name from a pvc://<claim>/<path> URL.
+func PVCClaim(p string) (string, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPVC, p)
+	}
+	return u.Host, nil
+}
+
+// PVCClaims returns the unique claims referenced by paths.
+func PVCClaims(paths []string) ([]string, error) {
+	var claims []string
+	for _, p := range paths {
+		if !IsPVC(p) {
+			continue
+		}
+		claim, err := PVCClaim(p)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(claims, claim) {
+			claims = append(claims, claim)
+		}
+	}
+	return claims, nil
+}
+
+// PVCPath returns the path to a pvc:// file in the job pod.
+func PVCPath(p string) (string, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPVC, p)
+	}
+	// Paths are cleaned from the root so they cannot leave the claim
+	return path.Join(util.PVCMountPath(u.Host), path.Clean("/"+u.Path)), nil
+}
+
+// PVCNames returns the claims in the current namespace.
+func PVCNames(ctx context.Context) ([]string, error) {
+	client, err := kubernetes.NewClient(config.Global.Kubeconfig, config.Global.Context, config.Global.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.PersistentVolumeClaims().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, claim := range list.Items {
+		names = append(names, claim.Name)
+	}
+	return names, nil
+}
+
+// PVC reads and writes files on a PersistentVolumeClaim by running commands in a pod which mounts it.
+// Object names are relative to the root of the claim.
+type PVC struct {
+	claim     string
+	root      string
+	pod       corev1.Pod
+	container string
+	exec      func(ctx context.Context, opts kubernetes.ExecOptions) error
+}
+
+// NewPVC starts a helper pod which mounts the claim in path. The pod is deleted when kubedb exits.
+func NewPVC(ctx context.Context, p string) (*PVC, error) {
+	claim, err := PVCClaim(p)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewClient(config.Global.Kubeconfig, config.Global.Context, config.Global.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return newPVC(ctx, client, claim, config.Global.PVCAppPod)
+}
+
+// newPVC starts a helper pod for the claim.
+// If appPod is true, a running pod which already mounts the claim is used instead when there is one.
+func newPVC(ctx context.Context, client kubernetes.KubeClient, claim string, appPod bool) (*PVC, error) {
+	if appPod {
+		pods, err := client.Pods().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		if pod, container, root, ok := findPVCMount(pods.Items, claim); ok {
+			slog.Debug("Using pod which mounts claim", "claim", claim, "pod", pod.Name)
+			return NewPVCInPod(client, pod, container, claim, root), nil
+		}
+	}
+
+	pod, err := startPVCPod(ctx, client, claim)
+	if err != nil {
+		return nil, err
+	}
+	return NewPVCInPod(client, pod, "", claim, util.PVCMountPath(claim)), nil
+}
+
+// NewPVCInPod returns a client which runs commands in the given container.
+// The claim must be mounted at root.
+func NewPVCInPod(client kubernetes.KubeClient, pod corev1.Pod, container, claim, root string) *PVC {
+	return &PVC{
+		claim:     claim,
+		root:      root,
+		pod:       pod,
+		container: container,
+		exec:      client.Exec,
+	}
+}
+
+// findPVCMount finds a running pod with a container which mounts the whole claim.
+func findPVCMount(pods []corev1.Pod, claim string) (corev1.Pod, string, string, bool) {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != claim {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				for _, mount := range container.VolumeMounts {
+					if mount.Name == volume.Name && mount.SubPath == "" && mount.SubPathExpr == "" {
+						return pod, container.Name, mount.MountPath, true
+					}
+				}
+			}
+		}
+	}
+	return corev1.Pod{}, "", "", false
+}
+
+func startPVCPod(ctx context.Context, client kubernetes.KubeClient, claim string) (corev1.Pod, error) {
+	labels := map[string]string{
+		"app.kubernetes.io/name":      "kubedb",
+		"app.kubernetes.io/instance":  "kubedb",
+		"app.kubernetes.io/component": "pvc",
+		"app.kubernetes.io/version":   util.GetVersion(),
+		"sidecar.istio.io/inject":     "false",
+	}
+
+	slog.Info("Starting pod to access claim", "namespace", client.Namespace, "claim", claim)
+	pod, err := client.Pods().Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kubedb-pvc-",
+			Namespace:    client.Namespace,
+			Labels:       labels,
+			Annotations:  map[string]string{"linkerd.io/inject": "disabled"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: new(int64(0)),
+			ActiveDeadlineSeconds:         new(int64(time.Hour.Seconds())),
+			Containers: []corev1.Container{{
+				Name:            util.JobContainer,
+				Image:           util.UploaderImage(config.Global.ClusterUpload),
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"sleep", "infinity"},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "kubedb-pvc",
+					MountPath: util.PVCMountPath(claim),
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "kubedb-pvc",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return corev1.Pod{}, err
+	}
+
+	name := pod.Name
+	finalizer.Add(func(_ error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := client.Pods().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			slog.Error("Failed to delete pvc pod", "name", name, "error", err)
+		}
+	})
+
+	err = wait.PollUntilContextTimeout(ctx, time.Second, 2*time.Minute, true, func(ctx context.Context) (bool, error) {
+		current, err := client.Pods().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		pod = current
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, fmt.Errorf("%w: %s", ErrPVCPodFailed, name)
+		default:
+			return false, nil
+		}
+	})
+	if err != nil {
+		return corev1.Pod{}, err
+	}
+	return *pod, nil
+}
+
+// resolve returns the path of key in the pod.
+func (p *PVC) resolve(key string) (*url.URL, string, error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Host != p.claim {
+		return nil, "", fmt.Errorf("%w: %s", ErrPVCClaimMismatch, key)
+	}
+	return u, path.Join(p.root, path.Clean("/"+u.Path)), nil
+}
+
+func (p *PVC) run(ctx context.Context, cmd *command.Builder, stdin io.Reader, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	err := p.exec(ctx, kubernetes.ExecOptions{
+		Pod:         p.pod,
+		Container:   p.container,
+		Cmd:         cmd.String(),
+		Stdin:       stdin,
+		Stdout:      stdout,
+		Stderr:      &stderr,
+		DisablePing: true,
+	})
+	if err != nil && stderr.Len() != 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// ListBuckets lists the claims in the namespace.
+func (p *PVC) ListBuckets(ctx context.Context) iter.Seq2[*Bucket, error] {
+	return func(yield func(*Bucket, error) bool) {
+		names, err := PVCNames(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, name := range names {
+			if !yield(&Bucket{Name: name}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (p *PVC) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		u, _, err := p.resolve(key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		prefix := strings.TrimLeft(u.Path, "/")
+		dir := path.Dir(path.Clean("/" + prefix))
+		if strings.HasSuffix(prefix, "/") {
+			dir = path.Clean("/" + prefix)
+		}
+		podDir := path.Join(p.root, dir)
+
+		cmd := command.NewBuilder(
+			"[", "-d", podDir, "]", command.Raw("||"), "exit", "0", command.Raw(";"),
+			"find", podDir, "-mindepth", "1", "-maxdepth", "1", "-exec", "stat", "-c", "%Y %s %f %n", "{}", "+",
+		)
+		var stdout bytes.Buffer
+		if err := p.run(ctx, cmd, nil, &stdout); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		objects, err := parsePVCStat(&stdout, p.root)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, object := range objects {
+			if !strings.HasPrefix(object.Name, prefix) {
+				continue
+			}
+			if !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
+
+// parsePVCStat parses `stat -c "%Y %s %f %n"` output.
+// Objects are named relative to root, similar to S3 keys.
+func parsePVCStat(r io.Reader, root string) ([]*Object, error) {
+	objects := make(map[string]*Object)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) != 4 {
+			continue
+		}
+
+		modified, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		mode, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := strings.CutPrefix(path.Clean(fields[3]), path.Clean(root)+"/")
+		if !ok {
+			continue
+		}
+		object := &Object{
+			Name:         name,
+			LastModified: time.Unix(modified, 0),
+			Size:         size,
+		}
+		const typeMask, typeDir = 0o170000, 0o040000
+		if mode&typeMask == typeDir {
+			object.Name += "/"
+			object.IsDir = true
+			object.Size = 0
+		}
+		objects[object.Name] = object
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*Object, 0, len(objects))
+	for _, name := range slices.Sorted(maps.Keys(objects)) {
+		result = append(result, objects[name])
+	}
+	return result, nil
+}
+
+func (p *PVC) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
+	if opts.Encrypted() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedSSE, key)
+	}
+
+	_, podPath, err := p.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	tmp := podPath + ".tmp"
+	cmd := command.NewBuilder(
+		"mkdir", "-p", path.Dir(podPath), command.Raw("&&"),
+		"cat", command.Raw(">"), tmp, command.Raw("&&"),
+		"mv", tmp, podPath,
+	)
+	return p.run(ctx, cmd, r, nil)
+}
+
+func (p *PVC) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	_, podPath, err := p.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the file exists before streaming it
+	if err := p.run(ctx, command.NewBuilder("test", "-f", podPath), nil, nil); err != nil {
+		return nil, fmt.Errorf("%w: %s", fs.ErrNotExist, key)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		defer cancel()
+		_ = pw.CloseWithError(p.run(ctx, command.NewBuilder("cat", podPath), nil, pw))
+	}()
+	return &pvcReader{PipeReader: pr, cancel: cancel}, nil
+}
+
+type pvcReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *pvcReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+func (p *PVC) DeleteObject(ctx context.Context, key string) error {
+	_, podPath, err := p.resolve(key)
+	if err != nil {
+		return err
+	}
+	return p.run(ctx, command.NewBuilder("rm", podPath), nil, nil)
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestIsPVCDir(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"relative local", "test.sql", false},
+		{"s3 bucket", "s3://test", false},
+		{"claim", "pvc://backups", true},
+		{"file", "pvc://backups/test.sql", false},
+		{"dir", "pvc://backups/subdir/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPVCDir(tt.path))
+		})
+	}
+}
+
+func TestPVCPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"file", "pvc://backups/test.sql", "/kubedb-pvc/backups/test.sql", require.NoError},
+		{"nested", "pvc://backups/a/b/test.sql", "/kubedb-pvc/backups/a/b/test.sql", require.NoError},
+		{"escape", "pvc://backups/../other/test.sql", "/kubedb-pvc/backups/other/test.sql", require.NoError},
+		{"no claim", "pvc:///test.sql", "", require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PVCPath(tt.path)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPVCClaims(t *testing.T) {
+	got, err := PVCClaims([]string{"pvc://a/1.sql", "s3://b/1.sql", "pvc://c/", "pvc://a/2.sql"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, got)
+}
+
+func Test_findPVCMount(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, subPath string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+					},
+				}},
+				Containers: []corev1.Container{
+					{Name: "sidecar"},
+					{Name: "app", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data", SubPath: subPath}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	pods := []corev1.Pod{
+		pod("pending", corev1.PodPending, ""),
+		pod("subpath", corev1.PodRunning, "sub"),
+		pod("running", corev1.PodRunning, ""),
+	}
+
+	got, container, root, ok := findPVCMount(pods, "backups")
+	require.True(t, ok)
+	assert.Equal(t, "running", got.Name)
+	assert.Equal(t, "app", container)
+	assert.Equal(t, "/data", root)
+
+	_, _, _, ok = findPVCMount(pods, "other")
+	assert.False(t, ok)
+}
+
+func Test_newPVC(t *testing.T) {
+	appPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+				},
+			}},
+			Containers: []corev1.Container{
+				{Name: "app", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	newClient := func(t *testing.T) kubernetes.KubeClient {
+		t.Helper()
+		clientset := kubernetesfake.NewClientset(appPod)
+		// The fake clientset neither generates names nor runs pods
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod) //nolint:errcheck,forcetypeassert
+			pod.Name = pod.GenerateName + "abc"
+			pod.Status.Phase = corev1.PodRunning
+			return false, nil, nil
+		})
+		return kubernetes.KubeClient{ClientSet: clientset, Namespace: "test"}
+	}
+
+	t.Run("helper pod", func(t *testing.T) {
+		p, err := newPVC(t.Context(), newClient(t), "backups", false)
+		require.NoError(t, err)
+		assert.Equal(t, "kubedb-pvc-abc", p.pod.Name)
+		assert.Equal(t, "/kubedb-pvc/backups", p.root)
+	})
+
+	t.Run("app pod", func(t *testing.T) {
+		p, err := newPVC(t.Context(), newClient(t), "backups", true)
+		require.NoError(t, err)
+		assert.Equal(t, "app", p.pod.Name)
+		assert.Equal(t, "app", p.container)
+		assert.Equal(t, "/data", p.root)
+	})
+}
+
+func TestPVC_ListObjects(t *testing.T) {
+	const stat = "1709643845 1024 81a4 /data/db/a.sql.gz\n" +
+		"1709643845 4096 41ed /data/db/sub\n" +
+		"1709643845 10 81a4 /data/db/other file.sql\n"
+
+	var cmd string
+	p := &PVC{
+		claim: "backups",
+		root:  "/data",
+		exec: func(_ context.Context, opts kubernetes.ExecOptions) error {
+			cmd = opts.Cmd
+			_, err := io.WriteString(opts.Stdout, stat)
+			return err
+		},
+	}
+
+	objects := make([]Object, 0, 3)
+	for object, err := range p.ListObjects(t.Context(), "pvc://backups/db/") {
+		require.NoError(t, err)
+		objects = append(objects, *object)
+	}
+	assert.Contains(t, cmd, "find /data/db ")
+	assert.Equal(t, []Object{
+		{Name: "db/a.sql.gz", LastModified: time.Unix(1709643845, 0), Size: 1024},
+		{Name: "db/other file.sql", LastModified: time.Unix(1709643845, 0), Size: 10},
+		{Name: "db/sub/", IsDir: true, LastModified: time.Unix(1709643845, 0)},
+	}, objects)
+
+	objects = objects[:0]
+	for object, err := range p.ListObjects(t.Context(), "pvc://backups/db/a") {
+		require.NoError(t, err)
+		objects = append(objects, *object)
+	}
+	require.Len(t, objects, 1)
+	assert.Equal(t, "db/a.sql.gz", objects[0].Name)
+
+	for _, err := range p.ListObjects(t.Context(), "pvc://other/db/") {
+		require.ErrorIs(t, err, ErrPVCClaimMismatch)
+	}
+}
+
+func TestPVC_PutObject(t *testing.T) {
+	var cmd string
+	var got strings.Builder
+	p := &PVC{
+		claim: "backups",
+		root:  "/data",
+		exec: func(_ context.Context, opts kubernetes.ExecOptions) error {
+			cmd = opts.Cmd
+			_, err := io.Copy(&got, opts.Stdin)
+			return err
+		},
+	}
+
+	require.NoError(t, p.PutObject(t.Context(), strings.NewReader("SELECT 1;"), "pvc://backups/db/a.sql", PutOptions{}))
+	assert.Equal(t, "mkdir -p /data/db && cat > /data/db/a.sql.tmp && mv /data/db/a.sql.tmp /data/db/a.sql", cmd)
+	assert.Equal(t, "SELECT 1;", got.String())
+
+	err := p.PutObject(t.Context(), strings.NewReader(""), "pvc://backups/a.sql", PutOptions{SSE: SSES3})
+	require.ErrorIs(t, err, ErrUnsupportedSSE)
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+)
+
+const RemoteSchema = "remote://"
+
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, RemoteSchema)
+}
+
+func IsRemoteDir(path string) bool {
+	if !IsRemote(path) {
+		return false
+	}
+	if strings.HasSuffix(path, "/") {
+		return true
+	}
+	trimmed := strings.TrimPrefix(path, RemoteSchema)
+	return !strings.Contains(trimmed, "/")
+}
+
+var (
+	ErrUnknownRemote = errors.New("unknown remote")
+	ErrInvalidRemote = errors.New("invalid remote")
+)
+
+// RemoteNames returns the sorted names of all configured remotes.
+func RemoteNames() []string {
+	return slices.Sorted(maps.Keys(config.Global.Storage.Remotes))
+}
+
+// Remote resolves remote://<name>/<path> keys to the configured storage location.
+// Object names are relative to the remote's prefix.
+type Remote struct {
+	name   string
+	base   url.URL
+	prefix string
+	client Client
+}
+
+func NewRemote(ctx context.Context, path string) (*Remote, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	conf, ok := config.Global.Storage.Remotes[u.Host]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRemote, u.Host)
+	}
+
+	r, err := newRemote(u.Host, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.client, err = newClient(ctx, r.base.String(), conf); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func newRemote(name string, conf conftypes.Remote) (*Remote, error) {
+	if !IsCloud(conf.URL) || IsRemote(conf.URL) {
+		return nil, fmt.Errorf("%w: %s: url must be a storage URL like s3://bucket", ErrInvalidRemote, name)
+	}
+
+	base, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRemote, name, err)
+	}
+
+	var prefix []string
+	for _, p := range []string{base.Path, conf.Prefix} {
+		if p = strings.Trim(p, "/"); p != "" {
+			prefix = append(prefix, p)
+		}
+	}
+	base.Path = ""
+
+	return &Remote{
+		name:   name,
+		base:   *base,
+		prefix: strings.Join(prefix, "/"),
+	}, nil
+}
+
+// Resolve converts a remote:// key to the backing storage URL.
+func (r *Remote) Resolve(key string) (string, error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return "", err
+	}
+	if u.Host != r.name {
+		return "", fmt.Errorf("%w: %s", ErrUnknownRemote, u.Host)
+	}
+
+	resolved := r.base
+	resolved.Path = "/" + strings.TrimLeft(u.Path, "/")
+	if r.prefix != "" {
+		resolved.Path = "/" + r.prefix + resolved.Path
+	}
+	if resolved.RawQuery == "" {
+		resolved.RawQuery = u.RawQuery
+	}
+	return resolved.String(), nil
+}
+
+func (r *Remote) ListBuckets(context.Context) iter.Seq2[*Bucket, error] {
+	return func(yield func(*Bucket, error) bool) {
+		yield(&Bucket{Name: r.name}, nil)
+	}
+}
+
+func (r *Remote) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		resolved, err := r.Resolve(key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for object, err := range r.client.ListObjects(ctx, resolved) {
+			if err == nil && r.prefix != "" {
+				object.Name = strings.TrimPrefix(strings.TrimLeft(object.Name, "/"), r.prefix+"/")
+			}
+			if !yield(object, err) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Remote) PutObject(ctx context.Context, rd io.Reader, key string, opts PutOptions) error {
+	resolved, err := r.Resolve(key)
+	if err != nil {
+		return err
+	}
+	return r.client.PutObject(ctx, rd, resolved, opts)
+}
+
+func (r *Remote) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	resolved, err := r.Resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.GetObject(ctx, resolved)
+}
+
+func (r *Remote) DeleteObject(ctx context.Context, key string) error {
+	resolved, err := r.Resolve(key)
+	if err != nil {
+		return err
+	}
+	return r.client.DeleteObject(ctx, resolved)
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"iter"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRemoteDir(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"relative local", "test.sql", false},
+		{"s3 bucket", "s3://test", false},
+		{"remote", "remote://prod", true},
+		{"remote file", "remote://prod/test.sql", false},
+		{"remote dir", "remote://prod/subdir/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRemoteDir(tt.path))
+		})
+	}
+}
+
+func TestRemote_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    conftypes.Remote
+		key     string
+		want    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"bucket", conftypes.Remote{URL: "s3://bucket"}, "remote://prod/a.sql", "s3://bucket/a.sql", require.NoError},
+		{"root", conftypes.Remote{URL: "s3://bucket"}, "remote://prod", "s3://bucket/", require.NoError},
+		{
+			"prefix",
+			conftypes.Remote{URL: "s3://bucket/backups/", Prefix: "/staging/"},
+			"remote://prod/dir/a.sql",
+			"s3://bucket/backups/staging/dir/a.sql",
+			require.NoError,
+		},
+		{"prefix root", conftypes.Remote{URL: "gs://bucket", Prefix: "db"}, "remote://prod/", "gs://bucket/db/", require.NoError},
+		{"other remote", conftypes.Remote{URL: "s3://bucket"}, "remote://dev/a.sql", "", require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newRemote("prod", tt.conf)
+			require.NoError(t, err)
+			got, err := r.Resolve(tt.key)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewRemote_Invalid(t *testing.T) {
+	_, err := newRemote("prod", conftypes.Remote{URL: "/local/dir"})
+	require.ErrorIs(t, err, ErrInvalidRemote)
+	_, err = newRemote("prod", conftypes.Remote{URL: "remote://other"})
+	require.ErrorIs(t, err, ErrInvalidRemote)
+
+	config.Global.Storage.Remotes = map[string]conftypes.Remote{"prod": {URL: "s3://bucket"}}
+	t.Cleanup(func() {
+		config.Global.Storage.Remotes = nil
+	})
+	_, err = NewRemote(t.Context(), "remote://dev/a.sql")
+	require.ErrorIs(t, err, ErrUnknownRemote)
+	assert.Equal(t, []string{"prod"}, RemoteNames())
+}
+
+type listClient struct {
+	Client
+	key     string
+	objects []*Object
+}
+
+func (l *listClient) ListObjects(_ context.Context, key string) iter.Seq2[*Object, error] {
+	l.key = key
+	return func(yield func(*Object, error) bool) {
+		for _, object := range l.objects {
+			if !yield(object, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (l *listClient) PutObject(_ context.Context, _ io.Reader, key string, _ PutOptions) error {
+	l.key = key
+	return nil
+}
+
+func TestRemote_Objects(t *testing.T) {
+	client := &listClient{objects: []*Object{
+		{Name: "backups/dir/", IsDir: true},
+		{Name: "backups/a.sql"},
+	}}
+	r, err := newRemote("prod", conftypes.Remote{URL: "s3://bucket", Prefix: "backups"})
+	require.NoError(t, err)
+	r.client = client
+
+	var names []string
+	for object, err := range r.ListObjects(t.Context(), "remote://prod/") {
+		require.NoError(t, err)
+		names = append(names, object.Name)
+	}
+	assert.Equal(t, "s3://bucket/backups/", client.key)
+	assert.Equal(t, []string{"dir/", "a.sql"}, names)
+
+	require.NoError(t, r.PutObject(t.Context(), nil, "remote://prod/b.sql", PutOptions{}))
+	assert.Equal(t, "s3://bucket/backups/b.sql", client.key)
+}
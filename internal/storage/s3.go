@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"io"
 	"iter"
 	"net/url"
@@ -9,8 +11,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/clevyr/kubedb/internal/config/conftypes"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	"gopkg.in/ini.v1"
 )
 
@@ -32,7 +36,8 @@ func IsS3Dir(path string) bool {
 }
 
 type S3 struct {
-	client *minio.Client
+	client       *minio.Client
+	storageClass string
 }
 
 type s3Config struct {
@@ -41,8 +46,8 @@ type s3Config struct {
 	Secure   bool
 }
 
-func NewS3() (*S3, error) {
-	cfg, err := loadS3Config()
+func NewS3(remote conftypes.Remote) (*S3, error) {
+	cfg, err := loadS3Config(remote)
 	if err != nil {
 		return nil, err
 	}
@@ -51,12 +56,13 @@ func NewS3() (*S3, error) {
 		cfg.Region = ""
 	}
 
+	creds, err := s3Credentials(remote.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := &minio.Options{
-		Creds: credentials.NewChainCredentials([]credentials.Provider{
-			&credentials.EnvAWS{},
-			&credentials.FileAWSCredentials{},
-			&credentials.IAM{},
-		}),
+		Creds:  creds,
 		Secure: cfg.Secure,
 		Region: cfg.Region,
 	}
@@ -65,12 +71,40 @@ func NewS3() (*S3, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &S3{client: client}, nil
+	return &S3{client: client, storageClass: remote.StorageClass}, nil
 }
 
-func loadS3Config() (s3Config, error) {
-	endpoint := os.Getenv("AWS_ENDPOINT_URL")
-	region := os.Getenv("AWS_REGION")
+func s3Credentials(c conftypes.RemoteCredentials) (*credentials.Credentials, error) {
+	switch c.Source {
+	case conftypes.CredentialsDefault:
+		return credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		}), nil
+	case conftypes.CredentialsEnv:
+		return credentials.NewEnvAWS(), nil
+	case conftypes.CredentialsProfile, conftypes.CredentialsFile:
+		return credentials.NewFileAWSCredentials(c.File, c.Profile), nil
+	case conftypes.CredentialsStatic:
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return nil, fmt.Errorf("%w: static credentials require an access key ID and secret access key", ErrInvalidRemote)
+		}
+		return credentials.NewStaticV4(c.AccessKeyID, c.SecretAccessKey, c.SessionToken), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported credentials source for s3: %s", ErrInvalidRemote, c.Source)
+	}
+}
+
+func loadS3Config(remote conftypes.Remote) (s3Config, error) {
+	endpoint := remote.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	region := remote.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
 
 	var cfg s3Config
 	var err error
@@ -87,7 +121,7 @@ func loadS3Config() (s3Config, error) {
 		return cfg, nil
 	}
 
-	fileEndpoint, fileRegion := loadConfigFromFile()
+	fileEndpoint, fileRegion := loadConfigFromFile(remote.Credentials.Profile)
 
 	if cfg.Endpoint == "" && fileEndpoint != "" {
 		cfg.Endpoint, cfg.Secure, err = parseEndpoint(fileEndpoint)
@@ -109,7 +143,7 @@ func loadS3Config() (s3Config, error) {
 	return cfg, nil
 }
 
-func loadConfigFromFile() (string, string) {
+func loadConfigFromFile(profile string) (string, string) {
 	configFile := os.Getenv("AWS_CONFIG_FILE")
 	if configFile == "" {
 		home, err := os.UserHomeDir()
@@ -124,7 +158,9 @@ func loadConfigFromFile() (string, string) {
 		return "", ""
 	}
 
-	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
 	if profile == "" {
 		profile = "default"
 	}
@@ -222,15 +258,29 @@ func (s *S3) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, err
 	}
 }
 
-func (s *S3) PutObject(ctx context.Context, r io.Reader, key string) error {
+func (s *S3) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
 	u, err := url.Parse(key)
 	if err != nil {
 		return err
 	}
 	u.Path = strings.TrimLeft(u.Path, "/")
 
+	putOpts := minio.PutObjectOptions{
+		StorageClass: cmp.Or(opts.StorageClass, s.storageClass),
+		UserTags:     opts.Tags,
+		UserMetadata: opts.Metadata,
+	}
+	switch {
+	case opts.SSE == SSEKMS || opts.KMSKeyID != "":
+		if putOpts.ServerSideEncryption, err = encrypt.NewSSEKMS(opts.KMSKeyID, nil); err != nil {
+			return err
+		}
+	case opts.SSE == SSES3:
+		putOpts.ServerSideEncryption = encrypt.NewSSE()
+	}
+
 	// -1 for object size tells MinIO to automatically use multipart upload
-	_, err = s.client.PutObject(ctx, u.Host, u.Path, r, -1, minio.PutObjectOptions{})
+	_, err = s.client.PutObject(ctx, u.Host, u.Path, r, -1, putOpts)
 	return err
 }
 
@@ -243,3 +293,13 @@ func (s *S3) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
 
 	return s.client.GetObject(ctx, u.Host, u.Path, minio.GetObjectOptions{})
 }
+
+func (s *S3) DeleteObject(ctx context.Context, key string) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimLeft(u.Path, "/")
+
+	return s.client.RemoveObject(ctx, u.Host, u.Path, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,346 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const SFTPSchema = "sftp://"
+
+func IsSFTP(path string) bool {
+	return strings.HasPrefix(path, SFTPSchema)
+}
+
+func IsSFTPDir(path string) bool {
+	if !IsSFTP(path) {
+		return false
+	}
+	if strings.HasSuffix(path, "/") {
+		return true
+	}
+	trimmed := strings.TrimPrefix(path, SFTPSchema)
+	return !strings.Contains(trimmed, "/")
+}
+
+type SFTP struct {
+	auth           []ssh.AuthMethod
+	knownHostsPath string
+	hostKeys       ssh.HostKeyCallback
+}
+
+const (
+	sftpKeyFileEnv    = "SFTP_KEY_FILE"
+	sftpKnownHostsEnv = "SFTP_KNOWN_HOSTS"
+)
+
+func NewSFTP() (*SFTP, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SFTP{}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			slog.Debug("Failed to connect to ssh-agent", "error", err)
+		} else {
+			s.auth = append(s.auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	keyFiles := []string{
+		filepath.Join(home, ".ssh", "id_ed25519"),
+		filepath.Join(home, ".ssh", "id_ecdsa"),
+		filepath.Join(home, ".ssh", "id_rsa"),
+	}
+	if keyFile := os.Getenv(sftpKeyFileEnv); keyFile != "" {
+		keyFiles = []string{keyFile}
+	}
+	signers := make([]ssh.Signer, 0, len(keyFiles))
+	for _, keyFile := range keyFiles {
+		b, err := os.ReadFile(keyFile)
+		if err != nil {
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			slog.Debug("Skipping ssh key", "path", keyFile, "error", err)
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) != 0 {
+		s.auth = append(s.auth, ssh.PublicKeys(signers...))
+	}
+
+	if len(s.auth) == 0 {
+		return nil, errors.NewUnauthorized(
+			"sftp unauthorized: please start ssh-agent or set " + sftpKeyFileEnv,
+		)
+	}
+
+	s.knownHostsPath = os.Getenv(sftpKnownHostsEnv)
+	if s.knownHostsPath == "" {
+		s.knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	if s.hostKeys, err = knownhosts.New(s.knownHostsPath); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type sftpClient struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+func (c *sftpClient) Close() error {
+	_ = c.Client.Close()
+	return c.conn.Close()
+}
+
+func (s *SFTP) dial(ctx context.Context, u *url.URL) (*sftpClient, error) {
+	username := u.User.Username()
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		username = current.Username
+	}
+
+	auth := s.auth
+	if password, ok := u.User.Password(); ok {
+		auth = append([]ssh.AuthMethod{ssh.Password(password)}, auth...)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, chans, reqs, err := ssh.NewClientConn(netConn, addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: s.hostKeys,
+	})
+	if err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	sshClient := ssh.NewClient(conn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, err
+	}
+
+	return &sftpClient{Client: client, conn: sshClient}, nil
+}
+
+// ListBuckets lists the hosts in known_hosts, since SFTP has no concept of buckets.
+func (s *SFTP) ListBuckets(_ context.Context) iter.Seq2[*Bucket, error] {
+	return func(yield func(*Bucket, error) bool) {
+		f, err := os.Open(s.knownHostsPath)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		seen := make(map[string]struct{})
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			// Skip comments, markers, and hashed hostnames
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") ||
+				strings.HasPrefix(fields[0], "@") || strings.HasPrefix(fields[0], "|") {
+				continue
+			}
+
+			for host := range strings.SplitSeq(fields[0], ",") {
+				if strings.HasPrefix(host, "[") {
+					// [host]:port
+					host = strings.Replace(strings.TrimPrefix(host, "["), "]", "", 1)
+				}
+				if _, ok := seen[host]; ok || strings.ContainsAny(host, "*?!") {
+					continue
+				}
+				seen[host] = struct{}{}
+
+				if !yield(&Bucket{Name: host}, nil) {
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+func (s *SFTP) ListObjects(ctx context.Context, key string) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		u, err := url.Parse(key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		client, err := s.dial(ctx, u)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer func() {
+			_ = client.Close()
+		}()
+
+		dir := u.Path
+		if !strings.HasSuffix(dir, "/") {
+			dir = path.Dir(dir)
+			if !strings.HasSuffix(dir, "/") {
+				dir += "/"
+			}
+		}
+
+		entries, err := client.ReadDirContext(ctx, dir)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, entry := range entries {
+			name := dir + entry.Name()
+			if !strings.HasPrefix(name, u.Path) {
+				continue
+			}
+
+			myObj := &Object{
+				// Names are relative to the root, like object keys
+				Name:         strings.TrimPrefix(name, "/"),
+				LastModified: entry.ModTime(),
+				Size:         entry.Size(),
+			}
+			if entry.IsDir() {
+				myObj.Name += "/"
+				myObj.IsDir = true
+			}
+
+			if !yield(myObj, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *SFTP) PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error {
+	if opts.Encrypted() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedSSE, key)
+	}
+
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if err := client.MkdirAll(path.Dir(u.Path)); err != nil {
+		return err
+	}
+
+	f, err := client.Create(u.Path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = client.Remove(u.Path)
+		return err
+	}
+	return f.Close()
+}
+
+type sftpFile struct {
+	*sftp.File
+	client *sftpClient
+}
+
+func (f *sftpFile) Close() error {
+	_ = f.File.Close()
+	return f.client.Close()
+}
+
+func (s *SFTP) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.dial(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := client.Open(u.Path)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return &sftpFile{File: f, client: client}, nil
+}
+
+func (s *SFTP) DeleteObject(ctx context.Context, key string) error {
+	u, err := url.Parse(key)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	return client.Remove(u.Path)
+}
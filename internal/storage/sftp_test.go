@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/storage/sftptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSFTP(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"relative local", args{"test.sql"}, false},
+		{"absolute local", args{"/home/test/test.sql"}, false},
+		{"sftp host", args{"sftp://user@test"}, true},
+		{"sftp file", args{"sftp://user@test/test.sql"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsSFTP(tt.args.path))
+		})
+	}
+}
+
+func TestIsSFTPDir(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"relative local", args{"test.sql"}, false},
+		{"absolute local", args{"/home/test/test.sql"}, false},
+		{"sftp host", args{"sftp://user@test"}, true},
+		{"sftp file", args{"sftp://user@test/test.sql"}, false},
+		{"sftp dir", args{"sftp://user@test/subdir/"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsSFTPDir(tt.args.path))
+		})
+	}
+}
+
+func TestSFTP_ListObjects(t *testing.T) {
+	addr := sftptest.NewServer(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.sql"), nil, 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+
+	client, err := NewSFTP()
+	require.NoError(t, err)
+
+	var names []string
+	for object, err := range client.ListObjects(t.Context(), "sftp://test@"+addr+dir+"/") {
+		require.NoError(t, err)
+		names = append(names, object.Name)
+	}
+
+	// Names are relative to the root, like object keys
+	prefix := strings.TrimPrefix(dir, "/") + "/"
+	assert.ElementsMatch(t, []string{prefix + "a.sql", prefix + "sub/"}, names)
+}
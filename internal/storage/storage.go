@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
 )
 
 type Bucket struct {
@@ -25,28 +27,88 @@ type Object struct {
 type Client interface {
 	ListBuckets(ctx context.Context) iter.Seq2[*Bucket, error]
 	ListObjects(ctx context.Context, key string) iter.Seq2[*Object, error]
-	PutObject(ctx context.Context, r io.Reader, key string) error
+	PutObject(ctx context.Context, r io.Reader, key string, opts PutOptions) error
 	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, key string) error
+}
+
+const (
+	SSES3  = "AES256"
+	SSEKMS = "aws:kms"
+)
+
+var (
+	ErrInvalidSSE     = errors.New("invalid server-side encryption")
+	ErrUnsupportedSSE = errors.New("server-side encryption is not supported")
+)
+
+// PutOptions are applied to uploaded objects.
+// Backends ignore options they do not support, except for encryption which returns ErrUnsupportedSSE.
+type PutOptions struct {
+	SSE          string
+	KMSKeyID     string
+	StorageClass string
+	Tags         map[string]string
+	Metadata     map[string]string
+}
+
+// Encrypted returns true if server-side encryption was requested.
+func (o PutOptions) Encrypted() bool {
+	return o.SSE != "" || o.KMSKeyID != ""
+}
+
+func (o PutOptions) Validate() error {
+	switch o.SSE {
+	case "", SSES3, SSEKMS:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSSE, o.SSE)
+	}
+	if o.SSE == SSES3 && o.KMSKeyID != "" {
+		return fmt.Errorf("%w: a KMS key ID requires %s", ErrInvalidSSE, SSEKMS)
+	}
+	return nil
 }
 
 func IsCloud(path string) bool {
-	return IsS3(path) || IsGCS(path) || IsB2(path)
+	return IsS3(path) || IsGCS(path) || IsB2(path) || IsAzBlob(path) || IsSFTP(path) ||
+		IsRemote(path) || IsPVC(path)
 }
 
 func IsCloudDir(path string) bool {
-	return IsS3Dir(path) || IsGCSDir(path) || IsB2Dir(path)
+	return IsS3Dir(path) || IsGCSDir(path) || IsB2Dir(path) || IsAzBlobDir(path) || IsSFTPDir(path) ||
+		IsRemoteDir(path) || IsPVCDir(path)
 }
 
 var ErrUnknownPrefix = errors.New("unknown prefix")
 
 func NewClient(ctx context.Context, path string) (Client, error) {
+	switch {
+	case IsRemote(path):
+		return NewRemote(ctx, path)
+	case IsPVC(path):
+		return NewPVC(ctx, path)
+	}
+	return newClient(ctx, path, conftypes.Remote{})
+}
+
+func newClient(ctx context.Context, path string, remote conftypes.Remote) (Client, error) {
 	switch {
 	case IsS3(path):
-		return NewS3()
+		return NewS3(remote)
 	case IsGCS(path):
-		return NewGCS(ctx, storage.ScopeReadWrite, "")
+		return NewGCS(ctx, storage.ScopeReadWrite, "", remote)
 	case IsB2(path):
-		return NewB2(ctx)
+		return NewB2(ctx, remote)
+	case IsAzBlob(path):
+		return NewAzBlob(remote)
+	case IsSFTP(path), IsHTTP(path):
+		if remote.Endpoint != "" || remote.Region != "" || remote.StorageClass != "" || remote.Credentials != (conftypes.RemoteCredentials{}) {
+			return nil, fmt.Errorf("%w: only url and prefix are supported for %s", ErrInvalidRemote, path)
+		}
+		if IsSFTP(path) {
+			return NewSFTP()
+		}
+		return NewHTTP(config.Global.Storage.HTTP), nil
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnknownPrefix, path)
 	}
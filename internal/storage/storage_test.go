@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCloud(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"local", "test.sql", false},
+		{"s3", "s3://test/test.sql", true},
+		{"sftp", "sftp://example.com/test.sql", true},
+		{"pvc", "pvc://backups/test.sql", true},
+		{"http", "https://example.com/test.sql", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsCloud(tt.path))
+		})
+	}
+}
+
+func TestPutOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PutOptions
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"empty", PutOptions{}, require.NoError},
+		{"sse-s3", PutOptions{SSE: SSES3}, require.NoError},
+		{"sse-kms", PutOptions{SSE: SSEKMS, KMSKeyID: "key"}, require.NoError},
+		{"kms key only", PutOptions{KMSKeyID: "key"}, require.NoError},
+		{"sse-s3 with kms key", PutOptions{SSE: SSES3, KMSKeyID: "key"}, require.Error},
+		{"invalid", PutOptions{SSE: "rot13"}, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantErr(t, tt.opts.Validate())
+		})
+	}
+}
@@ -58,3 +58,23 @@ func MinimalTable(r *lipgloss.Renderer) *Table {
 			}),
 	}
 }
+
+func ListTable(r *lipgloss.Renderer, headers ...string) *Table {
+	if r == nil {
+		r = Renderer
+	}
+	colStyle := TextStyle(r).Padding(0, 1)
+	headerStyle := colStyle.Bold(true)
+
+	return &Table{
+		Table: table.New().
+			BorderStyle(BorderStyle(r)).
+			Headers(headers...).
+			StyleFunc(func(row, _ int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return colStyle
+			}),
+	}
+}
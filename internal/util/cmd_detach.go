@@ -0,0 +1,129 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// OutputAnnotation lists the outputs of a detached job.
+	OutputAnnotation = "kubedb.clevyr.com/output"
+	// DetachedLabel is set on jobs which run without kubedb connected.
+	DetachedLabel = "kubedb.clevyr.com/detached"
+)
+
+// Detached describes a job which runs to completion without kubedb connected.
+type Detached struct {
+	// Env is stored in a secret and loaded by every container
+	Env map[string]string
+	// Commands replace the command of each named container
+	Commands map[string][]string
+	// Annotations are added to the job
+	Annotations map[string]string
+}
+
+type detachedJob struct {
+	Detached
+	secret string
+}
+
+func (d detachedJob) apply(job *batchv1.Job) {
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string, len(d.Annotations))
+	}
+	maps.Copy(job.Annotations, d.Annotations)
+	job.Labels[DetachedLabel] = "true"
+
+	tmpl := &job.Spec.Template
+	for i, container := range tmpl.Spec.Containers {
+		if command, ok := d.Commands[container.Name]; ok {
+			tmpl.Spec.Containers[i].Command = command
+		}
+		tmpl.Spec.Containers[i].EnvFrom = append(tmpl.Spec.Containers[i].EnvFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: d.secret},
+			},
+		})
+	}
+}
+
+func jobOwner(job *batchv1.Job) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       job.Name,
+		UID:        job.UID,
+	}}
+}
+
+// CreateDetachedJob creates a job which runs the given commands, then waits for it to start.
+// Unlike CreateJob, the job is not deleted when kubedb exits.
+func CreateDetachedJob(ctx context.Context, cmd *cobra.Command, conf *conftypes.Global, detached Detached) error {
+	secretCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	secret, err := conf.Client.Secrets().Create(secretCtx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kubedb-" + cmd.Name() + "-",
+			Namespace:    conf.Namespace,
+			Labels:       JobLabels(cmd.Name()),
+		},
+		StringData: detached.Env,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := createJob(ctx, conf, cmd.Name(), &detachedJob{Detached: detached, secret: secret.Name}); err != nil {
+		deleteSecret(ctx, conf, secret.Name)
+		return err
+	}
+
+	if err := ownSecret(ctx, conf, secret); err != nil {
+		return err
+	}
+
+	if err := watchJobPod(ctx, conf); err != nil && !errors.Is(err, ErrJobPodEarlyExit) {
+		return err
+	}
+	return nil
+}
+
+// ownSecret makes the job own the secret, so it is deleted along with the job.
+// If that fails, both are deleted so the secret cannot outlive the job.
+func ownSecret(ctx context.Context, conf *conftypes.Global, secret *corev1.Secret) error {
+	updateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	secret.OwnerReferences = jobOwner(conf.Job)
+	if _, err := conf.Client.Secrets().Update(updateCtx, secret, metav1.UpdateOptions{}); err != nil {
+		deleteSecret(ctx, conf, secret.Name)
+
+		deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		background := metav1.DeletePropagationBackground
+		if err := conf.Client.Jobs().Delete(deleteCtx, conf.Job.Name, metav1.DeleteOptions{PropagationPolicy: &background}); err != nil {
+			slog.Warn("Failed to delete job", "name", conf.Job.Name, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func deleteSecret(ctx context.Context, conf *conftypes.Global, name string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	if err := conf.Client.Secrets().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		slog.Warn("Failed to delete secret", "name", name, "error", err)
+	}
+}
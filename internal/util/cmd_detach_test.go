@@ -0,0 +1,90 @@
+package util
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_detachedJob_apply(t *testing.T) {
+	job := batchv1.Job{}
+	job.Labels = JobLabels("dump")
+	job.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: JobContainer, Command: []string{"sleep", "infinity"}},
+		{Name: UploaderContainer, Command: []string{"sleep", "infinity"}},
+	}
+
+	detachedJob{
+		Detached: Detached{
+			Commands:    map[string][]string{JobContainer: {"sh", "-c", "true"}},
+			Annotations: map[string]string{OutputAnnotation: "s3://bucket/a.sql"},
+		},
+		secret: "kubedb-dump-abc",
+	}.apply(&job)
+
+	assert.Equal(t, "true", job.Labels[DetachedLabel])
+	assert.Equal(t, "s3://bucket/a.sql", job.Annotations[OutputAnnotation])
+
+	containers := job.Spec.Template.Spec.Containers
+	assert.Equal(t, []string{"sh", "-c", "true"}, containers[0].Command)
+	assert.Equal(t, []string{"sleep", "infinity"}, containers[1].Command)
+	for _, c := range containers {
+		require.Len(t, c.EnvFrom, 1)
+		assert.Equal(t, "kubedb-dump-abc", c.EnvFrom[0].SecretRef.Name)
+	}
+}
+
+func Test_ownSecret(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{Name: "kubedb-dump-abc", Namespace: "test", UID: "abc"}
+	newConf := func(t *testing.T) (*conftypes.Global, *kubernetesfake.Clientset) {
+		t.Helper()
+		job := &batchv1.Job{ObjectMeta: objectMeta}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: objectMeta.Name, Namespace: "test"}}
+		clientset := kubernetesfake.NewClientset(job, secret)
+		return &conftypes.Global{
+			Kubernetes: conftypes.Kubernetes{Client: kubernetes.KubeClient{ClientSet: clientset, Namespace: "test"}},
+			Job:        job,
+		}, clientset
+	}
+
+	t.Run("owned", func(t *testing.T) {
+		conf, _ := newConf(t)
+		secret, err := conf.Client.Secrets().Get(t.Context(), objectMeta.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, ownSecret(t.Context(), conf, secret))
+
+		got, err := conf.Client.Secrets().Get(t.Context(), objectMeta.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, got.OwnerReferences, 1)
+		assert.Equal(t, objectMeta.UID, got.OwnerReferences[0].UID)
+	})
+
+	t.Run("update failed", func(t *testing.T) {
+		conf, clientset := newConf(t)
+		errUpdate := errors.New("update failed")
+		clientset.PrependReactor("update", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errUpdate
+		})
+		secret, err := conf.Client.Secrets().Get(t.Context(), objectMeta.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, ownSecret(t.Context(), conf, secret), errUpdate)
+
+		_, err = conf.Client.Secrets().Get(t.Context(), objectMeta.Name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+		_, err = conf.Client.Jobs().Get(t.Context(), objectMeta.Name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
@@ -206,20 +206,26 @@ func DefaultSetup(cmd *cobra.Command, conf *conftypes.Global) error {
 		mask.Add(conf.Password)
 	}
 
+	setupJob(conf)
+	return nil
+}
+
+// setupJob runs the database client in the database pod if no job will be created.
+func setupJob(conf *conftypes.Global) {
 	if db, ok := conf.Dialect.(conftypes.DBCanDisableJob); ok && db.DisableJob() {
+		// conf was unmarshalled before the dialect was detected, so setting the key alone has no effect
 		must.Must(config.K.Set(consts.FlagCreateJob, false))
+		conf.CreateJob = false
 	}
 	if !conf.CreateJob {
 		conf.Host = "127.0.0.1"
 		conf.JobPod = conf.DBPod
 	}
-
-	return nil
 }
 
 func CreateJob(ctx context.Context, cmd *cobra.Command, conf *conftypes.Global) error {
 	if conf.CreateJob {
-		if err := createJob(ctx, conf, cmd.Name()); err != nil {
+		if err := createJob(ctx, conf, cmd.Name(), nil); err != nil {
 			return err
 		}
 		finalizer.Add(func(_ error) {
@@ -233,7 +239,7 @@ func CreateJob(ctx context.Context, cmd *cobra.Command, conf *conftypes.Global)
 	return nil
 }
 
-func createJob(ctx context.Context, conf *conftypes.Global, actionName string) error {
+func createJob(ctx context.Context, conf *conftypes.Global, actionName string, detached *detachedJob) error {
 	defaultContainer := conf.DBPod.Spec.Containers[0]
 	if name := conf.DBPod.Annotations[podcmd.DefaultContainerAnnotationName]; name != "" {
 		for _, container := range conf.DBPod.Spec.Containers {
@@ -249,12 +255,7 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 		name += actionName + "-"
 	}
 
-	standardLabels := map[string]string{
-		"app.kubernetes.io/name":      "kubedb",
-		"app.kubernetes.io/instance":  "kubedb",
-		"app.kubernetes.io/component": actionName,
-		"app.kubernetes.io/version":   GetVersion(),
-	}
+	standardLabels := JobLabels(actionName)
 
 	podLabels := map[string]string{
 		"sidecar.istio.io/inject": "false",
@@ -320,7 +321,7 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 					},
 					Containers: []corev1.Container{
 						{
-							Name:            "kubedb",
+							Name:            JobContainer,
 							Image:           defaultContainer.Image,
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							Command:         []string{"sleep", "infinity"},
@@ -333,6 +334,24 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 		},
 	}
 
+	if db, ok := conf.Dialect.(conftypes.DBJobVolumer); ok {
+		volumes, mounts, err := db.JobVolumes(conf, defaultContainer)
+		if err != nil {
+			return err
+		}
+		addJobVolumes(&job.Spec.Template, conf.DBPod.Spec.NodeName, volumes, mounts)
+	}
+	if conf.ClusterUpload.Enabled {
+		addUploader(&job.Spec.Template, conf.ClusterUpload)
+	}
+	addClaims(&job.Spec.Template, conf.Claims)
+	if conf.Stage {
+		addStage(&job.Spec.Template)
+	}
+	if detached != nil {
+		detached.apply(&job)
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
@@ -344,7 +363,7 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 	}
 
 	if conf.CreateNetworkPolicy {
-		jobPodKey, jobPodVal := jobPodNameLabel(conf, conf.Job)
+		jobPodKey, jobPodVal := JobPodNameLabel(conf.Client, conf.Job.Name)
 		policy := networkingv1.NetworkPolicy{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      conf.Job.Name,
@@ -357,21 +376,32 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 				}},
 				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
 				Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
-				Egress: []networkingv1.NetworkPolicyEgressRule{
-					{
-						To: []networkingv1.NetworkPolicyPeer{{
-							NamespaceSelector: new(metav1.LabelSelector{MatchLabels: map[string]string{
-								"kubernetes.io/metadata.name": conf.Client.Namespace,
-							}}),
-						}},
-						Ports: []networkingv1.NetworkPolicyPort{{
-							Port: new(intstr.FromInt32(int32(conf.Port))),
-						}},
-					},
-				},
 			},
 		}
 
+		// File-based databases are read from a volume, so they need no egress
+		if conf.Port != 0 {
+			policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: new(metav1.LabelSelector{MatchLabels: map[string]string{
+						"kubernetes.io/metadata.name": conf.Client.Namespace,
+					}}),
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Port: new(intstr.FromInt32(int32(conf.Port))),
+				}},
+			})
+		}
+
+		if conf.ClusterUpload.Enabled {
+			policy.Spec.Egress = append(policy.Spec.Egress, uploaderEgress(conf.ClusterUpload.Ports))
+		}
+
+		if detached != nil {
+			// Detached jobs are not torn down, so delete the policy with the job
+			policy.OwnerReferences = jobOwner(conf.Job)
+		}
+
 		nsLog.Debug("Creating network policy")
 		if _, err := conf.Client.NetworkPolicies().Create(ctx, &policy, metav1.CreateOptions{}); err != nil {
 			nsLog.Warn("Failed to create network policy", "error", err)
@@ -382,6 +412,154 @@ func createJob(ctx context.Context, conf *conftypes.Global, actionName string) e
 	return nil
 }
 
+// uploaderEgress allows DNS and the storage ports so the uploader can reach the outputs.
+func uploaderEgress(ports []int32) networkingv1.NetworkPolicyEgressRule {
+	rule := networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: new(corev1.ProtocolUDP), Port: new(intstr.FromInt32(53))},
+			{Protocol: new(corev1.ProtocolTCP), Port: new(intstr.FromInt32(53))},
+		},
+	}
+	for _, port := range ports {
+		rule.Ports = append(rule.Ports, networkingv1.NetworkPolicyPort{
+			Protocol: new(corev1.ProtocolTCP),
+			Port:     new(intstr.FromInt32(port)),
+		})
+	}
+	return rule
+}
+
+// JobLabels returns the labels added to every kubedb job.
+func JobLabels(actionName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      "kubedb",
+		"app.kubernetes.io/instance":  "kubedb",
+		"app.kubernetes.io/component": actionName,
+		"app.kubernetes.io/version":   GetVersion(),
+	}
+}
+
+const (
+	JobContainer      = "kubedb"
+	UploaderContainer = "uploader"
+	UploadDir         = "/kubedb"
+	PVCDir            = "/kubedb-pvc"
+	StageDir          = "/kubedb-stage"
+)
+
+// UploaderImage returns the image which runs uploads in the job pod.
+// It defaults to the kubedb image matching the current version.
+func UploaderImage(conf conftypes.ClusterUpload) string {
+	if conf.Image != "" {
+		return conf.Image
+	}
+	tag := GetVersion()
+	if tag == "" || tag == "0.0.0" {
+		tag = "latest"
+	}
+	return "ghcr.io/clevyr/kubedb:" + tag
+}
+
+// addUploader adds a container which uploads dumps to cloud storage.
+// Dumps are passed to it through a shared volume.
+func addUploader(tmpl *corev1.PodTemplateSpec, conf conftypes.ClusterUpload) {
+	const volumeName = "kubedb-upload"
+	mount := corev1.VolumeMount{Name: volumeName, MountPath: UploadDir}
+
+	tmpl.Annotations[podcmd.DefaultContainerAnnotationName] = JobContainer
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+		Name:         volumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	tmpl.Spec.Containers[0].VolumeMounts = append(tmpl.Spec.Containers[0].VolumeMounts, mount)
+
+	uploader := corev1.Container{
+		Name:            UploaderContainer,
+		Image:           UploaderImage(conf),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"sleep", "infinity"},
+		VolumeMounts:    []corev1.VolumeMount{mount},
+		SecurityContext: tmpl.Spec.Containers[0].SecurityContext,
+	}
+	if conf.Secret != "" {
+		uploader.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: conf.Secret},
+			},
+		}}
+	}
+	tmpl.Spec.Containers = append(tmpl.Spec.Containers, uploader)
+
+	if conf.ServiceAccount != "" {
+		tmpl.Spec.ServiceAccountName = conf.ServiceAccount
+	}
+}
+
+// addStage mounts an emptyDir in the job container where dumps are staged.
+func addStage(tmpl *corev1.PodTemplateSpec) {
+	const volumeName = "kubedb-stage"
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+		Name:         volumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	tmpl.Spec.Containers[0].VolumeMounts = append(tmpl.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: StageDir,
+	})
+}
+
+// PVCMountPath returns where a claim is mounted in the job pod.
+func PVCMountPath(claim string) string {
+	return PVCDir + "/" + claim
+}
+
+// addJobVolumes mounts volumes from the database pod in the job container.
+// ReadWriteOnce claims can only attach to one node, so the job is pinned to the database pod's node.
+func addJobVolumes(tmpl *corev1.PodTemplateSpec, node string, volumes []corev1.Volume, mounts []corev1.VolumeMount) {
+	if len(volumes) == 0 {
+		return
+	}
+	tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, volumes...)
+	tmpl.Spec.Containers[0].VolumeMounts = append(tmpl.Spec.Containers[0].VolumeMounts, mounts...)
+
+	if node == "" {
+		return
+	}
+	if tmpl.Spec.Affinity == nil {
+		tmpl.Spec.Affinity = &corev1.Affinity{}
+	}
+	tmpl.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{node},
+				}},
+			}},
+		},
+	}
+}
+
+// addClaims mounts each claim in every container.
+func addClaims(tmpl *corev1.PodTemplateSpec, claims []string) {
+	for i, claim := range claims {
+		name := "kubedb-pvc-" + strconv.Itoa(i)
+		tmpl.Spec.Volumes = append(tmpl.Spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+		for j := range tmpl.Spec.Containers {
+			tmpl.Spec.Containers[j].VolumeMounts = append(tmpl.Spec.Containers[j].VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: PVCMountPath(claim),
+			})
+		}
+	}
+}
+
 var (
 	ErrJobPodFailed    = errors.New("job pod failed")
 	ErrJobPodEarlyExit = errors.New("job pod exited early")
@@ -481,8 +659,9 @@ func jobPodLabelSelector(conf *conftypes.Global, job *batchv1.Job) string {
 	return k + "=" + v
 }
 
-func jobPodNameLabel(conf *conftypes.Global, job *batchv1.Job) (string, string) {
-	useNewLabel, err := conf.Client.MinServerVersion(1, 27)
+// JobPodNameLabel returns the label Kubernetes sets on the pods of the named job.
+func JobPodNameLabel(client kubernetes.KubeClient, name string) (string, string) {
+	useNewLabel, err := client.MinServerVersion(1, 27)
 	if err != nil {
 		slog.Warn("Failed to query server version; assuming v1.27+", "error", err)
 		useNewLabel = true
@@ -494,7 +673,7 @@ func jobPodNameLabel(conf *conftypes.Global, job *batchv1.Job) (string, string)
 	} else {
 		key = "job-name"
 	}
-	return key, job.Name
+	return key, name
 }
 
 func checkNamespaceExists(ctx context.Context, conf *conftypes.Global) {
@@ -0,0 +1,147 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/clevyr/kubedb/internal/config"
+	"github.com/clevyr/kubedb/internal/config/conftypes"
+	"github.com/clevyr/kubedb/internal/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/util/podcmd"
+)
+
+type noJobDialect struct {
+	conftypes.Database
+}
+
+func (noJobDialect) DisableJob() bool { return true }
+
+func Test_setupJob(t *testing.T) {
+	t.Cleanup(func() {
+		config.K.Delete(consts.FlagCreateJob)
+	})
+	dbPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db"}}
+
+	tests := []struct {
+		name          string
+		conf          *conftypes.Global
+		wantCreateJob bool
+	}{
+		{"job", &conftypes.Global{CreateJob: true, Host: "10.0.0.1", DBPod: dbPod}, true},
+		{"disabled by flag", &conftypes.Global{DBPod: dbPod}, false},
+		{"disabled by dialect", &conftypes.Global{CreateJob: true, Dialect: noJobDialect{}, DBPod: dbPod}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupJob(tt.conf)
+			assert.Equal(t, tt.wantCreateJob, tt.conf.CreateJob)
+			if tt.wantCreateJob {
+				assert.Equal(t, "10.0.0.1", tt.conf.Host)
+				assert.Empty(t, tt.conf.JobPod.Name)
+			} else {
+				assert.Equal(t, "127.0.0.1", tt.conf.Host)
+				assert.Equal(t, "db", tt.conf.JobPod.Name)
+			}
+		})
+	}
+}
+
+func TestUploaderImage(t *testing.T) {
+	assert.Equal(t, "example.com/kubedb:dev", UploaderImage(conftypes.ClusterUpload{Image: "example.com/kubedb:dev"}))
+	assert.Contains(t, UploaderImage(conftypes.ClusterUpload{}), "ghcr.io/clevyr/kubedb:")
+}
+
+func Test_addUploader(t *testing.T) {
+	tmpl := corev1.PodTemplateSpec{}
+	tmpl.Annotations = map[string]string{}
+	tmpl.Spec.Containers = []corev1.Container{{Name: JobContainer}}
+
+	addUploader(&tmpl, conftypes.ClusterUpload{Secret: "creds", ServiceAccount: "backups"})
+
+	assert.Equal(t, JobContainer, tmpl.Annotations[podcmd.DefaultContainerAnnotationName])
+	assert.Equal(t, "backups", tmpl.Spec.ServiceAccountName)
+	require.Len(t, tmpl.Spec.Volumes, 1)
+	require.Len(t, tmpl.Spec.Containers, 2)
+
+	job, uploader := tmpl.Spec.Containers[0], tmpl.Spec.Containers[1]
+	assert.Equal(t, UploaderContainer, uploader.Name)
+	require.Len(t, uploader.EnvFrom, 1)
+	assert.Equal(t, "creds", uploader.EnvFrom[0].SecretRef.Name)
+	for _, c := range []corev1.Container{job, uploader} {
+		require.Len(t, c.VolumeMounts, 1)
+		assert.Equal(t, UploadDir, c.VolumeMounts[0].MountPath)
+	}
+}
+
+func Test_addJobVolumes(t *testing.T) {
+	volumes := []corev1.Volume{{Name: "data"}}
+	mounts := []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}
+
+	t.Run("no volumes", func(t *testing.T) {
+		tmpl := corev1.PodTemplateSpec{}
+		tmpl.Spec.Containers = []corev1.Container{{Name: JobContainer}}
+
+		addJobVolumes(&tmpl, "node-a", nil, nil)
+
+		assert.Empty(t, tmpl.Spec.Volumes)
+		assert.Nil(t, tmpl.Spec.Affinity)
+	})
+
+	t.Run("pinned to node", func(t *testing.T) {
+		tmpl := corev1.PodTemplateSpec{}
+		tmpl.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+		tmpl.Spec.Containers = []corev1.Container{{Name: JobContainer}}
+
+		addJobVolumes(&tmpl, "node-a", volumes, mounts)
+
+		assert.Equal(t, volumes, tmpl.Spec.Volumes)
+		assert.Equal(t, mounts, tmpl.Spec.Containers[0].VolumeMounts)
+		assert.NotNil(t, tmpl.Spec.Affinity.PodAffinity)
+		terms := tmpl.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		require.Len(t, terms, 1)
+		assert.Equal(t, []corev1.NodeSelectorRequirement{{
+			Key:      "metadata.name",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"node-a"},
+		}}, terms[0].MatchFields)
+	})
+}
+
+func Test_addClaims(t *testing.T) {
+	tmpl := corev1.PodTemplateSpec{}
+	tmpl.Spec.Containers = []corev1.Container{{Name: JobContainer}, {Name: UploaderContainer}}
+
+	addClaims(&tmpl, []string{"backups", "archive"})
+
+	require.Len(t, tmpl.Spec.Volumes, 2)
+	assert.Equal(t, "archive", tmpl.Spec.Volumes[1].PersistentVolumeClaim.ClaimName)
+	for _, c := range tmpl.Spec.Containers {
+		require.Len(t, c.VolumeMounts, 2)
+		assert.Equal(t, "/kubedb-pvc/backups", c.VolumeMounts[0].MountPath)
+		assert.Equal(t, tmpl.Spec.Volumes[1].Name, c.VolumeMounts[1].Name)
+	}
+}
+
+func Test_addStage(t *testing.T) {
+	tmpl := corev1.PodTemplateSpec{}
+	tmpl.Spec.Containers = []corev1.Container{{Name: JobContainer}}
+
+	addStage(&tmpl)
+
+	require.Len(t, tmpl.Spec.Volumes, 1)
+	assert.NotNil(t, tmpl.Spec.Volumes[0].EmptyDir)
+	require.Len(t, tmpl.Spec.Containers[0].VolumeMounts, 1)
+	assert.Equal(t, StageDir, tmpl.Spec.Containers[0].VolumeMounts[0].MountPath)
+}
+
+func Test_uploaderEgress(t *testing.T) {
+	rule := uploaderEgress([]int32{443, 9000})
+	ports := make([]string, 0, len(rule.Ports))
+	for _, p := range rule.Ports {
+		ports = append(ports, string(*p.Protocol)+"/"+p.Port.String())
+	}
+	assert.Equal(t, []string{"UDP/53", "TCP/53", "TCP/443", "TCP/9000"}, ports)
+}
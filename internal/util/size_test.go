@@ -0,0 +1,37 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"empty", "", 0, require.NoError},
+		{"bytes", "512", 512, require.NoError},
+		{"binary", "20MiB", 20 << 20, require.NoError},
+		{"decimal", "20MB", 20e6, require.NoError},
+		{"single letter", "500k", 500 << 10, require.NoError},
+		{"fraction", "1.5G", 1.5 * (1 << 30), require.NoError},
+		{"space", "2 TiB", 2 << 40, require.NoError},
+		{"unknown unit", "5 parsecs", 0, require.Error},
+		{"no number", "MiB", 0, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, ErrInvalidSize)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}